@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/schema"
+)
+
+// generates the JSON Schema of the settings file from the v1alpha1 types and writes it to the docs and the
+// generated go source used to validate settings files
+func main() {
+	dir, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+	data, err := schema.Generate(filepath.Join(dir, "pkg", "apis", "kubetest", "v1alpha1"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	schemaFile := filepath.Join(dir, "docs", "schema", "kubetest.json")
+	err = ioutil.WriteFile(schemaFile, data, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("generated %s\n", schemaFile)
+
+	source := fmt.Sprintf(`// Code generated by cmd/schema. DO NOT EDIT.
+
+package schema
+
+// JSON the JSON Schema of the KubeTest settings file
+const JSON = %s
+`, "`"+string(data)+"`")
+	sourceFile := filepath.Join(dir, "pkg", "schema", "zz_generated_schema.go")
+	err = ioutil.WriteFile(sourceFile, []byte(source), 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("generated %s\n", sourceFile)
+}
@@ -2,15 +2,21 @@ module github.com/jenkins-x-plugins/jx-kube-test
 
 require (
 	github.com/cpuguy83/go-md2man v1.0.10
+	github.com/google/cel-go v0.12.6
 	github.com/jenkins-x-plugins/jx-gitops v0.2.84
 	github.com/jenkins-x/jx-api/v4 v4.0.33
 	github.com/jenkins-x/jx-helpers/v3 v3.0.113
 	github.com/jenkins-x/jx-logging/v3 v3.0.6
+	github.com/open-policy-agent/opa v0.24.0
 	github.com/pkg/errors v0.9.1
+	github.com/sirupsen/logrus v1.7.0
 	github.com/spf13/cobra v1.1.1
 	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.7.0
+	github.com/xeipuuv/gojsonschema v1.2.0
+	gopkg.in/yaml.v3 v3.0.1
 	k8s.io/apimachinery v0.20.6
+	sigs.k8s.io/yaml v1.6.0
 )
 
 replace (
@@ -1,9 +1,17 @@
 package v1alpha1
 
 const (
+	// GroupName the API group of kube test
+	GroupName = "kubetest.jenkins-x.io"
+
 	// APIVersion the api version
-	APIVersion = "kubetest.jenkins-x.io/v1alpha1"
+	APIVersion = GroupName + "/v1alpha1"
 
 	// KindKubeTest the kind
 	KindKubeTest = "KubeTest"
+
+	// AnnotationIgnore the annotation on a resource to suppress checks on the resource. The value is a comma separated
+	// list of tool:checkID pairs such as 'kube-score:container-resources,polaris:runAsRootAllowed'. A tool without a
+	// check ID or with the check ID '*' suppresses all the checks of the tool
+	AnnotationIgnore = GroupName + "/ignore"
 )
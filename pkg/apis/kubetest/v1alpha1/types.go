@@ -39,8 +39,46 @@ type KubeTestSpec struct {
 	// OutputDir the output directory to store the reports
 	OutputDir string `json:"outputDir,omitempty"`
 
-	// Format the output format
+	// Format the output format. The json, junit and sarif formats are rendered from the results of the tools. Any other
+	// format such as tap is passed to each tool which is then run twice: once for the results and once for the report
 	Format string `json:"format,omitempty"`
+
+	// FailurePolicy the policy for how failing tests affect the result of the run
+	FailurePolicy FailurePolicy `json:"failurePolicy,omitempty"`
+
+	// KubernetesVersions the target kubernetes versions to template charts and helmfile releases and validate schemas
+	// against. If specified the charts and helmfiles are templated and the tests are run once for each version
+	KubernetesVersions []KubernetesVersion `json:"kubernetesVersions,omitempty"`
+
+	// Parallelism the maximum number of charts, values, overlays or resource dirs to template and test concurrently.
+	// Defaults to 1 which runs everything sequentially
+	Parallelism int `json:"parallelism,omitempty"`
+
+	// FailOn the minimum severity (info, warning, error or critical) of a failed check which fails a test.
+	// If not specified a test fails if the tool fails. Can be overridden for each test
+	FailOn string `json:"failOn,omitempty"`
+
+	// Duplicates if specified the resources in the resource dirs of all of the rules are checked for resources which
+	// are defined more than once or with conflicting namespaces
+	Duplicates *DuplicatesTest `json:"duplicates,omitempty"`
+}
+
+// KubernetesVersion a target kubernetes version to test the resources against
+type KubernetesVersion struct {
+	// Version the kubernetes version such as 1.21.1
+	Version string `json:"version"`
+
+	// APIVersions the additional api versions available in clusters of this version which are passed to helm template
+	APIVersions []string `json:"apiVersions,omitempty"`
+}
+
+// FailurePolicy the policy for how failing tests affect the result of the run
+type FailurePolicy struct {
+	// FailFast if enabled the run stops at the first failing test rather than running all of the remaining tests
+	FailFast bool `json:"failFast,omitempty"`
+
+	// Advisory the names of the tests (assertions, conftest, deprecations, duplicates, kubeconform, kubescore, kubeval, polaris, references, rego) whose failures are reported but do not fail the run
+	Advisory []string `json:"advisory,omitempty"`
 }
 
 // Rule the rules to apply
@@ -51,6 +89,12 @@ type Rule struct {
 	// Charts the charts to evaluate
 	Charts *Charts `json:"charts,omitempty"`
 
+	// Kustomize the kustomize overlays to build and evaluate
+	Kustomize *Kustomize `json:"kustomize,omitempty"`
+
+	// Helmfile the helmfile releases to template and evaluate
+	Helmfile *Helmfile `json:"helmfile,omitempty"`
+
 	// Tests the tests to perform
 	Tests Tests `json:"tests,omitempty"`
 }
@@ -68,6 +112,58 @@ type Charts struct {
 
 	// Recurse if enabled recurse through the directory to find any Chart.yaml files
 	Recurse bool `json:"recurse,omitempty"`
+
+	// Cases the test cases to template each chart with in addition to the default values and any values files
+	// found in the .jx-kube-test directory of the chart
+	Cases []ChartCase `json:"cases,omitempty"`
+}
+
+// ChartCase a named set of values and options used to template a chart as its own release
+type ChartCase struct {
+	// Name the name of the test case which must be unique for the charts
+	Name string `json:"name"`
+
+	// ValuesFiles the values files to pass to helm relative to the current directory
+	ValuesFiles []string `json:"valuesFiles,omitempty"`
+
+	// Values the inline values YAML to pass to helm after any values files
+	Values string `json:"values,omitempty"`
+
+	// Set the values to set on the command line such as 'image.tag=1.2.3'
+	Set []string `json:"set,omitempty"`
+
+	// Namespace the namespace to template the chart in
+	Namespace string `json:"namespace,omitempty"`
+
+	// ReleaseName the release name to template the chart with. If not specified the name of the case is used
+	ReleaseName string `json:"releaseName,omitempty"`
+
+	// KubeVersion the kubernetes version to use when templating the chart
+	KubeVersion string `json:"kubeVersion,omitempty"`
+
+	// APIVersions the kubernetes api versions to use for capabilities when templating the chart
+	APIVersions []string `json:"apiVersions,omitempty"`
+}
+
+// Kustomize the kustomize overlays to build and validate
+type Kustomize struct {
+	// Dir the directory containing the kustomization file or the overlays
+	Dir string `json:"dir,omitempty"`
+
+	// Overlays the overlay directories relative to the dir to build. If not specified the dir itself is built
+	Overlays []string `json:"overlays,omitempty"`
+}
+
+// Helmfile the helmfile releases to template and validate
+type Helmfile struct {
+	// File the helmfile to template. If not specified defaults to helmfile.yaml in the directory
+	File string `json:"file,omitempty"`
+
+	// Environments the helmfile environments to template each release in. If not specified the default environment is used
+	Environments []string `json:"environments,omitempty"`
+
+	// Selectors the optional label selectors to filter the releases to template such as 'name=myapp'
+	Selectors []string `json:"selectors,omitempty"`
 }
 
 // Test a kind of test
@@ -76,6 +172,26 @@ type Test struct {
 	Version string `json:"version,omitempty"`
 	// Args optional additional comand line arguments to pass to the test
 	Args []string `json:"args,omitempty"`
+	// FailOn the minimum severity (info, warning, error or critical) of a failed check which fails the test.
+	// If not specified the spec.failOn is used
+	FailOn string `json:"failOn,omitempty"`
+}
+
+// KubeconformTest the configuration of kubeconform tests
+type KubeconformTest struct {
+	Test `json:",inline"`
+
+	// SchemaLocations additional schema locations such as for custom resource definitions. The default schema location is always used
+	SchemaLocations []string `json:"schemaLocations,omitempty"`
+
+	// Strict if enabled disallows additional properties not in the schema
+	Strict bool `json:"strict,omitempty"`
+
+	// SkipKinds the kinds of resource to skip validating such as CustomResourceDefinition
+	SkipKinds []string `json:"skipKinds,omitempty"`
+
+	// IgnoreMissingSchemas if enabled skips resources with no schema
+	IgnoreMissingSchemas bool `json:"ignoreMissingSchemas,omitempty"`
 }
 
 // Tests the tests to run on the resources
@@ -86,9 +202,108 @@ type Tests struct {
 	// Kubescore enables kube-score based tests
 	Kubescore *Test `json:"kubescore,omitempty"`
 
+	// Kubeconform enables kubeconform tests
+	Kubeconform *KubeconformTest `json:"kubeconform,omitempty"`
+
 	// Kubeval enables kubeval tests
 	Kubeval *Test `json:"kubeval,omitempty"`
 
 	// Polaris enables polaris tests
 	Polaris *Test `json:"polaris,omitempty"`
+
+	// Deprecations enables the built in check for deprecated and removed kubernetes APIs
+	Deprecations *DeprecationsTest `json:"deprecations,omitempty"`
+
+	// Rego enables the built in evaluation of conftest compatible Rego policies without downloading conftest
+	Rego *RegoTest `json:"rego,omitempty"`
+
+	// Assertions the inline CEL assertions evaluated against each resource
+	Assertions []Assertion `json:"assertions,omitempty"`
+
+	// AssertionsFailOn the minimum severity (info, warning, error or critical) of a failed assertion which fails the
+	// test. If not specified the spec.failOn is used
+	AssertionsFailOn string `json:"assertionsFailOn,omitempty"`
+
+	// References enables the built in check that the resources refer to each other consistently such as Service
+	// selectors, Ingress backends and the ConfigMaps, Secrets and ServiceAccounts used by pods
+	References *ReferencesTest `json:"references,omitempty"`
+}
+
+// DeprecationsTest the configuration of the deprecated and removed kubernetes API check
+type DeprecationsTest struct {
+	// KubernetesVersion the target kubernetes version to check against if no spec.kubernetesVersions are specified.
+	// If neither are specified any deprecated API is reported
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+
+	// FailOn the minimum severity (warning for deprecated or error for removed APIs) which fails the test.
+	// If not specified the spec.failOn is used
+	FailOn string `json:"failOn,omitempty"`
+}
+
+// RegoTest the configuration of the built in evaluation of Rego policies
+type RegoTest struct {
+	// Policies the directories or files containing the Rego policies relative to the current directory.
+	// Defaults to the policy directory like conftest if no bundles are specified
+	Policies []string `json:"policies,omitempty"`
+
+	// Bundles the OPA bundle directories or tarballs containing policies and data
+	Bundles []string `json:"bundles,omitempty"`
+
+	// Data the directories or files of JSON or YAML documents available to the policies via the data keyword
+	Data []string `json:"data,omitempty"`
+
+	// Namespaces the packages of the policies to evaluate. Defaults to main like conftest
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// AllNamespaces if enabled the policies in all packages are evaluated
+	AllNamespaces bool `json:"allNamespaces,omitempty"`
+
+	// FailOn the minimum severity (warning for warn rules or error for deny and violation rules) which fails the test.
+	// If not specified the spec.failOn is used
+	FailOn string `json:"failOn,omitempty"`
+}
+
+// ReferencesTest the configuration of the check that the resources refer to each other consistently
+type ReferencesTest struct {
+	// Allow the resources which can be referenced without being defined in the resources as they are created
+	// elsewhere such as 'Secret/tls' or 'ServiceAccount/*'
+	Allow []string `json:"allow,omitempty"`
+
+	// FailOn the minimum severity which fails the test. If not specified the spec.failOn is used
+	FailOn string `json:"failOn,omitempty"`
+}
+
+// DuplicatesTest the configuration of the check for duplicate and conflicting resources across the resource dirs of
+// all of the rules
+type DuplicatesTest struct {
+	// ClusterScopedKinds the kinds of any additional cluster scoped resources such as custom resources whose
+	// CustomResourceDefinition is not in the resource dirs. Use 'Kind.group' such as 'ClusterIssuer.cert-manager.io'
+	// or just 'Kind' to match the kind in any API group. Custom resources whose kind is not listed here or in a
+	// CustomResourceDefinition in the resource dirs are treated as namespaced
+	ClusterScopedKinds []string `json:"clusterScopedKinds,omitempty"`
+
+	// FailOn the minimum severity which fails the test. If not specified the spec.failOn is used
+	FailOn string `json:"failOn,omitempty"`
+}
+
+// Assertion an inline check of the resources using a CEL expression
+type Assertion struct {
+	// Name the name of the assertion which is used as the check ID of the results
+	Name string `json:"name"`
+
+	// APIVersion the api version of the resources to check such as apps/v1. If not specified any api version is checked
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Kind the kind of the resources to check such as Deployment. If not specified any kind is checked
+	Kind string `json:"kind,omitempty"`
+
+	// Expression the CEL expression which must be true for each resource which is available as the object variable
+	// such as 'has(object.metadata.labels.team)'
+	Expression string `json:"expression"`
+
+	// Message the message reported if the expression is false. If not specified the expression is used
+	Message string `json:"message,omitempty"`
+
+	// Severity the severity (info, warning, error or critical) of a failure. Defaults to error
+	Severity string `json:"severity,omitempty"`
 }
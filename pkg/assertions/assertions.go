@@ -0,0 +1,141 @@
+package assertions
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ToolName the name of the inline CEL assertions used in results
+	ToolName = "assertions"
+
+	// ObjectVariable the name of the variable containing the resource in the expressions
+	ObjectVariable = "object"
+)
+
+// Checker evaluates the compiled assertions against kubernetes resources
+type Checker struct {
+	assertions []*assertion
+}
+
+// assertion a compiled assertion
+type assertion struct {
+	v1alpha1.Assertion
+	severity results.Severity
+	program  cel.Program
+}
+
+// Compile compiles the CEL expressions of the assertions returning an error for the first invalid assertion
+func Compile(assertions []v1alpha1.Assertion) (*Checker, error) {
+	env, err := cel.NewEnv(cel.Variable(ObjectVariable, cel.MapType(cel.StringType, cel.DynType)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the CEL environment")
+	}
+
+	c := &Checker{}
+	names := map[string]bool{}
+	for i := range assertions {
+		a := &assertions[i]
+		if a.Name == "" {
+			return nil, errors.Errorf("assertion %d has no name", i)
+		}
+		if names[a.Name] {
+			return nil, errors.Errorf("duplicate assertion name %s", a.Name)
+		}
+		names[a.Name] = true
+
+		severity := results.SeverityError
+		if a.Severity != "" {
+			severity, err = results.ParseSeverity(a.Severity)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid severity of assertion %s", a.Name)
+			}
+		}
+
+		ast, issues := env.Compile(a.Expression)
+		if issues != nil && issues.Err() != nil {
+			return nil, errors.Wrapf(issues.Err(), "failed to compile the expression of assertion %s", a.Name)
+		}
+		outputType := ast.OutputType().String()
+		if outputType != cel.BoolType.String() && outputType != cel.DynType.String() {
+			return nil, errors.Errorf("the expression of assertion %s returns %s rather than bool", a.Name, outputType)
+		}
+		program, err := env.Program(ast)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create the program of assertion %s", a.Name)
+		}
+		c.assertions = append(c.assertions, &assertion{
+			Assertion: *a,
+			severity:  severity,
+			program:   program,
+		})
+	}
+	return c, nil
+}
+
+// Check evaluates the assertions against each matching resource. If an expression cannot be evaluated on a resource
+// such as due to a missing field the assertion fails
+func (c *Checker) Check(resources []*manifests.Manifest, co *results.ResourceLocation) []*results.Result {
+	var answer []*results.Result
+	for _, m := range resources {
+		for _, a := range c.assertions {
+			if !a.matches(m) {
+				continue
+			}
+			r := &results.Result{
+				Tool:      ToolName,
+				Location:  co,
+				Kind:      m.Kind(),
+				Name:      m.Name(),
+				Namespace: m.Namespace(),
+				File:      m.File,
+				CheckID:   a.Name,
+				Status:    results.StatusPassed,
+			}
+			answer = append(answer, r)
+
+			ok, err := a.evaluate(m)
+			if ok {
+				continue
+			}
+			r.Status = results.StatusFailed
+			r.Severity = a.severity
+			r.Message = a.Message
+			if r.Message == "" {
+				r.Message = a.Expression
+			}
+			if err != nil {
+				r.Message = fmt.Sprintf("%s: %s", r.Message, err.Error())
+			}
+		}
+	}
+	return answer
+}
+
+// matches returns true if the assertion applies to the resource
+func (a *assertion) matches(m *manifests.Manifest) bool {
+	if a.Kind != "" && a.Kind != m.Kind() {
+		return false
+	}
+	return a.APIVersion == "" || a.APIVersion == m.Object.GetAPIVersion()
+}
+
+// evaluate returns true if the expression is true for the resource
+func (a *assertion) evaluate(m *manifests.Manifest) (bool, error) {
+	out, _, err := a.program.Eval(map[string]interface{}{
+		ObjectVariable: m.Object.Object,
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to evaluate the expression")
+	}
+	value, ok := out.Value().(bool)
+	if !ok {
+		return false, errors.Errorf("the expression returned %v rather than a bool", out.Value())
+	}
+	return value, nil
+}
@@ -0,0 +1,84 @@
+package assertions_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/assertions"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	resources, err := manifests.LoadFile(filepath.Join("test_data", "resources.yaml"))
+	require.NoError(t, err, "failed to load resources")
+	require.Len(t, resources, 3, "resources")
+
+	c, err := assertions.Compile([]v1alpha1.Assertion{
+		{
+			Name:       "team-label",
+			Kind:       "Deployment",
+			Expression: "has(object.metadata.labels) && has(object.metadata.labels.team)",
+			Message:    "deployments must have a team label",
+		},
+		{
+			Name:       "replicas",
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Expression: "object.spec.replicas >= 2",
+			Severity:   "warning",
+		},
+		{
+			Name:       "name-length",
+			Expression: "size(object.metadata.name) <= 20",
+		},
+		{
+			Name:       "other-api-version",
+			APIVersion: "apps/v1beta1",
+			Expression: "false",
+		},
+	})
+	require.NoError(t, err, "failed to compile assertions")
+
+	items := c.Check(resources, nil)
+
+	var actual []string
+	for _, r := range items {
+		actual = append(actual, r.String())
+	}
+	assert.Equal(t, []string{
+		"assertions passed team-label on Deployment myapp",
+		"assertions passed replicas on Deployment myapp",
+		"assertions passed name-length on Deployment myapp",
+		"assertions failed team-label on Deployment unowned: deployments must have a team label",
+		"assertions failed replicas on Deployment unowned: object.spec.replicas >= 2: failed to evaluate the expression: no such key: replicas",
+		"assertions passed name-length on Deployment unowned",
+		"assertions failed name-length on Service a-very-long-service-name: size(object.metadata.name) <= 20",
+	}, actual, "results")
+
+	severities := map[string]results.Severity{}
+	for _, r := range results.Failures(items) {
+		severities[r.CheckID] = r.Severity
+	}
+	assert.Equal(t, map[string]results.Severity{
+		"team-label":  results.SeverityError,
+		"replicas":    results.SeverityWarning,
+		"name-length": results.SeverityError,
+	}, severities, "severities")
+
+	invalid := map[string]v1alpha1.Assertion{
+		"syntax error":     {Name: "a", Expression: "object.metadata.name =="},
+		"non bool result":  {Name: "a", Expression: "1 + 2"},
+		"missing name":     {Expression: "true"},
+		"invalid severity": {Name: "a", Expression: "true", Severity: "fatal"},
+	}
+	for name, a := range invalid {
+		_, err = assertions.Compile([]v1alpha1.Assertion{a})
+		assert.Error(t, err, "should fail to compile %s", name)
+	}
+	_, err = assertions.Compile([]v1alpha1.Assertion{{Name: "a", Expression: "true"}, {Name: "a", Expression: "true"}})
+	assert.Error(t, err, "should fail on duplicate names")
+}
@@ -0,0 +1,173 @@
+package baseline
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/yamls"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultFileName the default name of the baseline file in the .jx/kube-test dir
+	DefaultFileName = "baseline.yaml"
+
+	// DateFormat the format of the expiry dates of waivers
+	DateFormat = "2006-01-02"
+)
+
+// Baseline the known findings which are accepted so that only new findings fail the run
+type Baseline struct {
+	// Waivers the accepted findings
+	Waivers []Waiver `json:"waivers"`
+}
+
+// Waiver an accepted finding of a tool on a resource
+type Waiver struct {
+	// Tool the name of the tool which reported the finding
+	Tool string `json:"tool"`
+
+	// CheckID the ID of the check which failed
+	CheckID string `json:"checkId,omitempty"`
+
+	// Kind the kind of the resource
+	Kind string `json:"kind,omitempty"`
+
+	// Name the name of the resource
+	Name string `json:"name,omitempty"`
+
+	// Namespace the namespace of the resource
+	Namespace string `json:"namespace,omitempty"`
+
+	// Chart the chart dir relative to the directory being tested if the resource was generated from a chart
+	Chart string `json:"chart,omitempty"`
+
+	// SourceDir the resource dir, kustomize overlay or helmfile dir relative to the directory being tested if the
+	// resource was not generated from a chart
+	SourceDir string `json:"sourceDir,omitempty"`
+
+	// Reason why the finding is accepted
+	Reason string `json:"reason,omitempty"`
+
+	// Expires the optional date in the format YYYY-MM-DD after which the finding is no longer accepted
+	Expires string `json:"expires,omitempty"`
+}
+
+// NewWaiver creates a waiver for the given failed result
+func NewWaiver(r *results.Result, chart, sourceDir string) Waiver {
+	return Waiver{
+		Tool:      r.Tool,
+		CheckID:   r.CheckID,
+		Kind:      r.Kind,
+		Name:      r.Name,
+		Namespace: r.Namespace,
+		Chart:     chart,
+		SourceDir: sourceDir,
+	}
+}
+
+// Key returns the key used to match the waiver to findings
+func (w *Waiver) Key() string {
+	return Key(w.Tool, w.CheckID, w.Kind, w.Namespace, w.Name, w.Chart, w.SourceDir)
+}
+
+// Key returns the key of a finding
+func Key(tool, checkID, kind, namespace, name, chart, sourceDir string) string {
+	return strings.Join([]string{tool, checkID, kind, namespace, name, chart, sourceDir}, "|")
+}
+
+// ResultKey returns the key of a result
+func ResultKey(r *results.Result, chart, sourceDir string) string {
+	return Key(r.Tool, r.CheckID, r.Kind, r.Namespace, r.Name, chart, sourceDir)
+}
+
+// CanWaive returns true if the failed result can be waived. The failures of a tool which did not report any
+// findings, such as when it crashes, are never waived so that they can't hide all future findings of the tool
+func CanWaive(r *results.Result) bool {
+	return r.CheckID != results.CheckToolError
+}
+
+// Expired returns true if the waiver has an expiry date which is before the given time
+func (w *Waiver) Expired(now time.Time) bool {
+	if w.Expires == "" {
+		return false
+	}
+	t, err := time.Parse(DateFormat, w.Expires)
+	if err != nil {
+		return false
+	}
+	// lets allow the whole of the expiry day
+	return now.After(t.AddDate(0, 0, 1))
+}
+
+// String returns a description of the waiver
+func (w *Waiver) String() string {
+	text := fmt.Sprintf("%s %s", w.Tool, w.CheckID)
+	resource := w.Name
+	if w.Namespace != "" {
+		resource = w.Namespace + "/" + resource
+	}
+	if w.Kind != "" {
+		resource = strings.TrimSpace(w.Kind + " " + resource)
+	}
+	if resource != "" {
+		text += " on " + resource
+	}
+	if w.Chart != "" {
+		text += " in chart " + w.Chart
+	}
+	if w.SourceDir != "" {
+		text += " in dir " + w.SourceDir
+	}
+	return text
+}
+
+// Validate validates the waivers
+func (b *Baseline) Validate() error {
+	for i := range b.Waivers {
+		w := &b.Waivers[i]
+		if w.Tool == "" {
+			return errors.Errorf("waiver %d has no tool", i)
+		}
+		if w.Expires != "" {
+			_, err := time.Parse(DateFormat, w.Expires)
+			if err != nil {
+				return errors.Wrapf(err, "waiver %d has an invalid expires date %s which should be in the format YYYY-MM-DD", i, w.Expires)
+			}
+		}
+	}
+	return nil
+}
+
+// Sort sorts the waivers so that the file is stable
+func (b *Baseline) Sort() {
+	sort.SliceStable(b.Waivers, func(i, j int) bool {
+		return b.Waivers[i].Key() < b.Waivers[j].Key()
+	})
+}
+
+// LoadFile loads and validates the baseline file
+func LoadFile(path string) (*Baseline, error) {
+	b := &Baseline{}
+	err := yamls.LoadFile(path, b)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load file %s", path)
+	}
+	err = b.Validate()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid baseline file %s", path)
+	}
+	return b, nil
+}
+
+// SaveFile saves the baseline file
+func SaveFile(path string, b *Baseline) error {
+	err := yamls.SaveFile(b, path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save file %s", path)
+	}
+	return nil
+}
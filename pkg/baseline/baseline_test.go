@@ -0,0 +1,75 @@
+package baseline_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/baseline"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join("test_data", "baseline.yaml")
+	b, err := baseline.LoadFile(path)
+	require.NoError(t, err, "failed to load %s", path)
+	require.Len(t, b.Waivers, 2, "waivers")
+
+	w := &b.Waivers[0]
+	assert.Equal(t, "kube-score container-resources on Deployment jx/myapp in chart charts/myapp", w.String(), "waiver")
+	assert.Equal(t, "legacy chart", w.Reason, "reason")
+
+	r := &results.Result{
+		Tool:      results.ToolKubeScore,
+		CheckID:   "container-resources",
+		Kind:      "Deployment",
+		Name:      "myapp",
+		Namespace: "jx",
+	}
+	assert.Equal(t, w.Key(), baseline.ResultKey(r, "charts/myapp", ""), "key of matching result")
+	assert.NotEqual(t, w.Key(), baseline.ResultKey(r, "charts/other", ""), "key of result in another chart")
+	assert.NotEqual(t, w.Key(), baseline.ResultKey(r, "", "config-root"), "key of result in a resource dir")
+	assert.True(t, baseline.CanWaive(r), "should waive a finding")
+
+	r.CheckID = results.CheckToolError
+	assert.False(t, baseline.CanWaive(r), "should not waive a tool error")
+
+	assert.False(t, w.Expired(time.Date(2021, 6, 30, 12, 0, 0, 0, time.UTC)), "should not expire during the expiry day")
+	assert.True(t, w.Expired(time.Date(2021, 7, 2, 0, 0, 0, 0, time.UTC)), "should expire after the expiry day")
+	assert.False(t, b.Waivers[1].Expired(time.Now()), "waiver without an expiry date should not expire")
+}
+
+func TestInvalidBaseline(t *testing.T) {
+	testCases := []struct {
+		name    string
+		waivers []baseline.Waiver
+		message string
+	}{
+		{
+			name:    "missing tool",
+			waivers: []baseline.Waiver{{CheckID: "schema"}},
+			message: "waiver 0 has no tool",
+		},
+		{
+			name:    "invalid date",
+			waivers: []baseline.Waiver{{Tool: "kubeval", Expires: "30/06/2021"}},
+			message: "invalid expires date 30/06/2021",
+		},
+	}
+
+	tmpDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	for _, tc := range testCases {
+		path := filepath.Join(tmpDir, "baseline.yaml")
+		err = baseline.SaveFile(path, &baseline.Baseline{Waivers: tc.waivers})
+		require.NoError(t, err, "failed to save baseline for %s", tc.name)
+
+		_, err = baseline.LoadFile(path)
+		require.Error(t, err, "should have failed to load baseline for %s", tc.name)
+		assert.Contains(t, err.Error(), tc.message, "error for %s", tc.name)
+	}
+}
@@ -0,0 +1,207 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultMaxSizeMB the default maximum size of the cache in megabytes
+	DefaultMaxSizeMB = 100
+
+	// entryExtension the file extension of cache entries
+	entryExtension = ".json"
+)
+
+// Entry the cached output of running a tool
+type Entry struct {
+	// Output the JSON output of the tool which is parsed into results
+	Output string `json:"output,omitempty"`
+
+	// Report the output of the tool in the report format if a report was saved
+	Report string `json:"report,omitempty"`
+
+	// Error the error message if the tool failed
+	Error string `json:"error,omitempty"`
+}
+
+// Cache a local cache of the output of tools keyed on a hash of the resources and the tool configuration so that
+// tools do not need to be run again on resources which have not changed
+type Cache struct {
+	Dir     string
+	MaxSize int64
+	lock    sync.Mutex
+}
+
+// NewCache creates a new cache in the given dir which is evicted when larger than the given size in bytes
+func NewCache(dir string, maxSize int64) *Cache {
+	return &Cache{
+		Dir:     dir,
+		MaxSize: maxSize,
+	}
+}
+
+// Get returns the cached entry for the key or nil if there is no entry
+func (c *Cache) Get(key string) (*Entry, error) {
+	path := c.entryPath(key)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read file %s", path)
+	}
+	entry := &Entry{}
+	err = json.Unmarshal(data, entry)
+	if err != nil {
+		// lets ignore corrupt entries so they get replaced
+		return nil, nil
+	}
+
+	// lets mark the entry as recently used so it is evicted last
+	now := time.Now()
+	err = os.Chtimes(path, now, now)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to touch file %s", path)
+	}
+	return entry, nil
+}
+
+// Put saves the entry for the key and evicts the least recently used entries if the cache is too large
+func (c *Cache) Put(key string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal cache entry")
+	}
+	err = os.MkdirAll(c.Dir, files.DefaultDirWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create dir %s", c.Dir)
+	}
+
+	// lets write to a temporary file first so that concurrent readers never see a partial entry
+	tmpFile, err := ioutil.TempFile(c.Dir, key+"-*.tmp")
+	if err != nil {
+		return errors.Wrapf(err, "failed to create temporary file in %s", c.Dir)
+	}
+	_, err = tmpFile.Write(data)
+	closeErr := tmpFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpFile.Name())
+		return errors.Wrapf(err, "failed to save file %s", tmpFile.Name())
+	}
+	path := c.entryPath(key)
+	err = os.Rename(tmpFile.Name(), path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to rename %s to %s", tmpFile.Name(), path)
+	}
+	return c.Evict()
+}
+
+// Evict removes the least recently used entries until the cache is no larger than the maximum size
+func (c *Cache) Evict() error {
+	if c.MaxSize <= 0 {
+		return nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	fs, err := ioutil.ReadDir(c.Dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read dir %s", c.Dir)
+	}
+	var entries []os.FileInfo
+	var size int64
+	for _, f := range fs {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), entryExtension) {
+			continue
+		}
+		entries = append(entries, f)
+		size += f.Size()
+	}
+	if size <= c.MaxSize {
+		return nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime().Before(entries[j].ModTime())
+	})
+	for _, f := range entries {
+		if size <= c.MaxSize {
+			break
+		}
+		path := filepath.Join(c.Dir, f.Name())
+		err = os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to remove file %s", path)
+		}
+		size -= f.Size()
+	}
+	return nil
+}
+
+func (c *Cache) entryPath(key string) string {
+	return filepath.Join(c.Dir, key+entryExtension)
+}
+
+// Hasher creates a cache key from the tool configuration and the content of files
+type Hasher struct {
+	hash hash.Hash
+}
+
+// NewHasher creates a new hasher
+func NewHasher() *Hasher {
+	return &Hasher{
+		hash: sha256.New(),
+	}
+}
+
+// AddString adds the given values to the key
+func (h *Hasher) AddString(values ...string) {
+	for _, v := range values {
+		// lets separate the values so that different splits of the same text have different keys
+		_, _ = io.WriteString(h.hash, v+"\x00")
+	}
+}
+
+// AddPath adds the relative names and content of the given file or all the files in the given dir to the key
+func (h *Hasher) AddPath(path string) error {
+	return filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(path, file)
+		if err != nil {
+			return errors.Wrapf(err, "failed to find relative path of %s", file)
+		}
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read file %s", file)
+		}
+		h.AddString(filepath.ToSlash(rel), string(data))
+		return nil
+	})
+}
+
+// Key returns the cache key for everything added to the hasher
+func (h *Hasher) Key() string {
+	return hex.EncodeToString(h.hash.Sum(nil))
+}
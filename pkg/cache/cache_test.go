@@ -0,0 +1,95 @@
+package cache_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	c := cache.NewCache(tmpDir, 0)
+
+	entry, err := c.Get("missing")
+	require.NoError(t, err, "failed to get missing entry")
+	assert.Nil(t, entry, "should not find a missing entry")
+
+	expected := &cache.Entry{
+		Output: `[{"status": "valid"}]`,
+		Error:  "kubeval failed",
+	}
+	err = c.Put("mykey", expected)
+	require.NoError(t, err, "failed to put entry")
+
+	entry, err = c.Get("mykey")
+	require.NoError(t, err, "failed to get entry")
+	assert.Equal(t, expected, entry, "cached entry")
+}
+
+func TestCacheEviction(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	output := strings.Repeat("x", 100)
+	c := cache.NewCache(tmpDir, 250)
+
+	old := time.Now().Add(-time.Hour)
+	for i, key := range []string{"a", "b"} {
+		err = c.Put(key, &cache.Entry{Output: output})
+		require.NoError(t, err, "failed to put entry %s", key)
+
+		// lets make sure the entries have different times
+		ts := old.Add(time.Duration(i) * time.Minute)
+		err = os.Chtimes(filepath.Join(tmpDir, key+".json"), ts, ts)
+		require.NoError(t, err, "failed to set times of %s", key)
+	}
+
+	// lets use the oldest entry so that it is not evicted
+	entry, err := c.Get("a")
+	require.NoError(t, err, "failed to get entry")
+	require.NotNil(t, entry, "should have found entry a")
+
+	err = c.Put("c", &cache.Entry{Output: output})
+	require.NoError(t, err, "failed to put entry c")
+
+	assert.FileExists(t, filepath.Join(tmpDir, "a.json"), "recently used entry should be kept")
+	assert.NoFileExists(t, filepath.Join(tmpDir, "b.json"), "least recently used entry should be evicted")
+	assert.FileExists(t, filepath.Join(tmpDir, "c.json"), "new entry should be kept")
+}
+
+func TestHasher(t *testing.T) {
+	dir1, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+	dir2, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	for _, dir := range []string{dir1, dir2} {
+		err = ioutil.WriteFile(filepath.Join(dir, "deployment.yaml"), []byte("kind: Deployment\n"), 0600)
+		require.NoError(t, err, "failed to write file")
+	}
+
+	key := func(dir string, args ...string) string {
+		h := cache.NewHasher()
+		h.AddString(args...)
+		err := h.AddPath(dir)
+		require.NoError(t, err, "failed to hash %s", dir)
+		return h.Key()
+	}
+
+	assert.Equal(t, key(dir1, "kubeval"), key(dir2, "kubeval"), "the same content in different dirs should have the same key")
+	assert.NotEqual(t, key(dir1, "kubeval"), key(dir1, "conftest"), "different tools should have different keys")
+	assert.NotEqual(t, key(dir1, "a", "bc"), key(dir1, "ab", "c"), "different arguments should have different keys")
+
+	err = ioutil.WriteFile(filepath.Join(dir2, "deployment.yaml"), []byte("kind: StatefulSet\n"), 0600)
+	require.NoError(t, err, "failed to write file")
+	assert.NotEqual(t, key(dir1, "kubeval"), key(dir2, "kubeval"), "different content should have different keys")
+}
@@ -0,0 +1,142 @@
+package baseline
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/baseline"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cache"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/run"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cobras/helper"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cobras/templates"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/termcolor"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	info = termcolor.ColorInfo
+
+	cmdLong = templates.LongDesc(`
+		Runs all of the kubernetes tests and records the current findings in the baseline file so that subsequent runs only fail on new findings
+`)
+
+	cmdExample = templates.Examples(`
+		# records the current findings in .jx/kube-test/baseline.yaml
+		jx kube test baseline --reason "legacy chart"
+
+		# records the current findings which are accepted until the end of the year
+		jx kube test baseline --reason "legacy chart" --expires 2021-12-31
+	`)
+)
+
+// Options the options for the command
+type Options struct {
+	run.Options
+
+	Reason  string
+	Expires string
+}
+
+// NewCmdBaseline creates a command object for the command
+func NewCmdBaseline() (*cobra.Command, *Options) {
+	o := &Options{}
+
+	// lets use the default cache size as the cache flags are only available on run
+	o.CacheMaxSize = cache.DefaultMaxSizeMB
+
+	cmd := &cobra.Command{
+		Use:     "baseline",
+		Short:   "Records the current findings in the baseline file so that only new findings fail the run",
+		Long:    cmdLong,
+		Example: cmdExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := o.Run()
+			helper.CheckErr(err)
+		},
+	}
+	o.Options.AddTargetFlags(cmd)
+	o.Options.AddBaselineFlags(cmd)
+
+	cmd.Flags().StringVarP(&o.Reason, "reason", "", "", "the reason recorded for the new waivers")
+	cmd.Flags().StringVarP(&o.Expires, "expires", "", "", "the date in the format YYYY-MM-DD when the new waivers expire")
+	return cmd, o
+}
+
+// Run implements the command
+func (o *Options) Run() error {
+	if o.Expires != "" {
+		_, err := time.Parse(baseline.DateFormat, o.Expires)
+		if err != nil {
+			return errors.Wrapf(err, "invalid --expires date %s which should be in the format YYYY-MM-DD", o.Expires)
+		}
+	}
+	err := o.Options.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "failed to validate")
+	}
+
+	// lets record all of the findings rather than waiving the existing ones
+	existing := o.Baseline
+	o.Baseline = nil
+	o.Settings.Spec.FailurePolicy.FailFast = false
+
+	err = o.TestRules()
+	if err != nil {
+		return errors.Wrapf(err, "failed to run the tests")
+	}
+
+	b := o.CreateBaseline(existing)
+	dir := filepath.Dir(o.BaselineFile)
+	err = os.MkdirAll(dir, files.DefaultDirWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create dir %s", dir)
+	}
+	err = baseline.SaveFile(o.BaselineFile, b)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save baseline")
+	}
+	log.Logger().Infof("saved %s waivers in %s", info(len(b.Waivers)), info(o.BaselineFile))
+	return nil
+}
+
+// CreateBaseline creates a baseline of the failed results of the run keeping the reason and expiry date of any
+// existing waivers which still match. The failures of tools which did not report any findings are not recorded
+func (o *Options) CreateBaseline(existing *baseline.Baseline) *baseline.Baseline {
+	previous := map[string]*baseline.Waiver{}
+	if existing != nil {
+		for i := range existing.Waivers {
+			w := &existing.Waivers[i]
+			previous[w.Key()] = w
+		}
+	}
+
+	b := &baseline.Baseline{}
+	found := map[string]bool{}
+	for _, r := range o.Results() {
+		if !r.Failed() || !baseline.CanWaive(r) {
+			continue
+		}
+		w := baseline.NewWaiver(r, o.BaselineChart(r.Location), o.BaselineSourceDir(r.Location))
+		key := w.Key()
+		if found[key] {
+			continue
+		}
+		found[key] = true
+
+		old := previous[key]
+		if old != nil {
+			w.Reason = old.Reason
+			w.Expires = old.Expires
+		} else {
+			w.Reason = o.Reason
+			w.Expires = o.Expires
+		}
+		b.Waivers = append(b.Waivers, w)
+	}
+	b.Sort()
+	return b
+}
@@ -0,0 +1,162 @@
+package baseline_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/baseline"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cache"
+	cmdbaseline "github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/baseline"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/run"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner/fakerunner"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseline(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	resourcesDir := filepath.Join("test_data", "resources")
+	otherDir := filepath.Join("test_data", "other")
+	deploymentFile := filepath.Join(resourcesDir, "deployment.yaml")
+	serviceFile := filepath.Join(resourcesDir, "service.yaml")
+	baselineFile := filepath.Join(tmpDir, "baseline.yaml")
+
+	invalid := func(files ...string) func(c *cmdrunner.Command) (string, error) {
+		return func(c *cmdrunner.Command) (string, error) {
+			text := "["
+			for i, f := range files {
+				if i > 0 {
+					text += ","
+				}
+				text += `{"filename": "` + f + `", "status": "invalid", "errors": ["bad"]}`
+			}
+			text += "]"
+			if len(files) == 0 {
+				return text, nil
+			}
+			return text, errors.Errorf("kubeval failed")
+		}
+	}
+	settings := func() *v1alpha1.KubeTest {
+		return &v1alpha1.KubeTest{
+			Spec: v1alpha1.KubeTestSpec{
+				Rules: []v1alpha1.Rule{
+					{
+						Resources: &v1alpha1.Source{
+							Dir: resourcesDir,
+						},
+						Tests: v1alpha1.Tests{
+							Kubeval: &v1alpha1.Test{},
+						},
+					},
+				},
+			},
+		}
+	}
+	runTests := func(fn func(c *cmdrunner.Command) (string, error)) (*run.Options, error) {
+		runner := &fakerunner.FakeRunner{
+			CommandRunner: fn,
+		}
+		_, o := run.NewCmdRun()
+		o.NoCache = true
+		o.Dir = "test_data"
+		o.BaselineFile = baselineFile
+		o.CommandRunner = runner.Run
+		o.KubevalPlugin.Binary = "kubeval"
+		o.Settings = settings()
+		err := o.Run()
+		return o, err
+	}
+
+	createBaseline := func(fn func(c *cmdrunner.Command) (string, error), s *v1alpha1.KubeTest) *cmdbaseline.Options {
+		cmd, bo := cmdbaseline.NewCmdBaseline()
+		assert.Nil(t, cmd.Flags().Lookup("changed-since"), "should not have the --changed-since flag")
+		assert.Nil(t, cmd.Flags().Lookup("output"), "should not have the --output flag")
+		bo.NoCache = true
+		bo.Dir = "test_data"
+		bo.BaselineFile = baselineFile
+		bo.Reason = "legacy resources"
+		bo.CommandRunner = (&fakerunner.FakeRunner{CommandRunner: fn}).Run
+		bo.KubevalPlugin.Binary = "kubeval"
+		bo.Settings = s
+		err := bo.Run()
+		require.NoError(t, err, "failed to create baseline")
+		return bo
+	}
+
+	// lets check the same resource in two resource dirs has separate waivers
+	s := settings()
+	s.Spec.Rules = append(s.Spec.Rules, v1alpha1.Rule{
+		Resources: &v1alpha1.Source{
+			Dir: otherDir,
+		},
+		Tests: v1alpha1.Tests{
+			Kubeval: &v1alpha1.Test{},
+		},
+	})
+	createBaseline(func(c *cmdrunner.Command) (string, error) {
+		return invalid(filepath.Join(c.Args[len(c.Args)-1], "deployment.yaml"))(c)
+	}, s)
+	b, err := baseline.LoadFile(baselineFile)
+	require.NoError(t, err, "failed to load baseline")
+	require.Len(t, b.Waivers, 2, "waivers of the same resource in two dirs")
+	assert.Equal(t, "other", b.Waivers[0].SourceDir, "source dir of first waiver")
+	assert.Equal(t, "resources", b.Waivers[1].SourceDir, "source dir of second waiver")
+
+	// lets check a tool which crashes is not waived
+	createBaseline(func(c *cmdrunner.Command) (string, error) {
+		return "panic: runtime error", errors.Errorf("kubeval crashed")
+	}, settings())
+	b, err = baseline.LoadFile(baselineFile)
+	require.NoError(t, err, "failed to load baseline")
+	assert.Empty(t, b.Waivers, "waivers of a crashed tool")
+
+	createBaseline(invalid(deploymentFile), settings())
+
+	b, err = baseline.LoadFile(baselineFile)
+	require.NoError(t, err, "failed to load baseline")
+	require.Len(t, b.Waivers, 1, "waivers")
+	assert.Equal(t, baseline.Waiver{
+		Tool:      results.ToolKubeval,
+		CheckID:   "schema",
+		Kind:      "Deployment",
+		Name:      "myapp",
+		Namespace: "jx",
+		SourceDir: "resources",
+		Reason:    "legacy resources",
+	}, b.Waivers[0], "waiver")
+
+	o, err := runTests(invalid(deploymentFile))
+	require.NoError(t, err, "should not fail on waived findings")
+	require.Len(t, o.Results(), 1, "results")
+	assert.Equal(t, results.StatusWaived, o.Results()[0].Status, "result status")
+	assert.Empty(t, o.StaleWaivers(), "stale waivers")
+
+	_, err = runTests(invalid(deploymentFile, serviceFile))
+	require.Error(t, err, "should fail on new findings")
+	assert.Contains(t, err.Error(), "kubeval failed on resources", "error")
+
+	o, err = runTests(invalid())
+	require.NoError(t, err, "should pass with no findings")
+	stale := o.StaleWaivers()
+	require.Len(t, stale, 1, "stale waivers")
+	assert.Equal(t, "Deployment", stale[0].Kind, "stale waiver kind")
+}
+
+func TestBaselineFlags(t *testing.T) {
+	cmd, o := cmdbaseline.NewCmdBaseline()
+	for _, name := range []string{"dir", "settings", "work-dir", "baseline", "reason", "expires"} {
+		assert.NotNil(t, cmd.Flags().Lookup(name), "should have flag --%s", name)
+	}
+	for _, name := range []string{"parallelism", "fail-on", "no-cache", "cache-dir", "cache-max-size", "output"} {
+		assert.Nil(t, cmd.Flags().Lookup(name), "should not have flag --%s", name)
+	}
+	assert.Equal(t, cache.DefaultMaxSizeMB, o.CacheMaxSize, "cache max size")
+}
@@ -0,0 +1,537 @@
+package initcmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/run"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cobras/helper"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cobras/templates"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/input"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/input/inputfactory"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/options"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/termcolor"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/yamls"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	yamlv3 "gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	info = termcolor.ColorInfo
+
+	cmdLong = templates.LongDesc(`
+		Creates the .jx/kube-test/settings.yaml file by detecting the resources, charts, chart values files and kustomize overlays in the current directory
+`)
+
+	cmdExample = templates.Examples(`
+		# creates the .jx/kube-test/settings.yaml file with the default tests
+		jx kube test init
+
+		# creates the settings file choosing which validators to enable
+		jx kube test init --interactive
+
+		# creates the settings file with the given validators
+		jx kube test init --tests kubeconform,kubescore,deprecations
+	`)
+
+	// Validators the names of the tests which can be enabled in the settings file
+	Validators = []string{"conftest", "deprecations", "kubeconform", "kubescore", "kubeval", "polaris", "references", "rego"}
+
+	// DefaultValidators the tests enabled by default
+	DefaultValidators = []string{"deprecations", "kubeval"}
+
+	// validatorDescriptions the descriptions of each validator used in the comments of the settings file
+	validatorDescriptions = map[string]string{
+		"conftest":     "checks the resources against the rego policies in the policy dir",
+		"deprecations": "checks for deprecated or removed kubernetes APIs",
+		"kubeconform":  "validates the resources against the kubernetes schemas",
+		"kubescore":    "checks the resources follow security and reliability best practices",
+		"kubeval":      "validates the resources against the kubernetes schemas",
+		"polaris":      "checks the resources follow security and reliability best practices",
+		"references":   "checks the resources only refer to services, config maps, secrets and workloads which are defined",
+		"rego":         "checks the resources against the rego policies in the policy dir without downloading conftest",
+	}
+
+	// kustomizationFiles the names of the kustomize files
+	kustomizationFiles = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+)
+
+// Options the options for the command
+type Options struct {
+	options.BaseOptions
+
+	Dir          string
+	SettingsFile string
+	Tests        []string
+	Interactive  bool
+	Overwrite    bool
+	Input        input.Interface
+	Settings     *v1alpha1.KubeTest
+
+	ruleComments []string
+}
+
+// kustomization the parts of a kustomization file which refer to other kustomizations
+type kustomization struct {
+	Resources  []string `json:"resources,omitempty"`
+	Bases      []string `json:"bases,omitempty"`
+	Components []string `json:"components,omitempty"`
+}
+
+// NewCmdInit creates a command object for the command
+func NewCmdInit() (*cobra.Command, *Options) {
+	o := &Options{}
+
+	cmd := &cobra.Command{
+		Use:     "init",
+		Short:   "Creates the settings file by detecting the resources, charts and kustomize overlays to test",
+		Long:    cmdLong,
+		Example: cmdExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := o.Run()
+			helper.CheckErr(err)
+		},
+	}
+	o.BaseOptions.AddBaseFlags(cmd)
+
+	cmd.Flags().StringVarP(&o.Dir, "dir", "d", ".", "the directory to look for the resources, charts and kustomize overlays. The paths in the settings file are relative to the current directory like those of the run command")
+	cmd.Flags().StringVarP(&o.SettingsFile, "file", "f", "", "the settings file to create. Defaults to $dir/.jx/kube-test/settings.yaml")
+	cmd.Flags().StringSliceVarP(&o.Tests, "tests", "t", nil, fmt.Sprintf("the validators to enable (%s). Defaults to %s", strings.Join(Validators, ", "), strings.Join(DefaultValidators, ", ")))
+	cmd.Flags().BoolVarP(&o.Interactive, "interactive", "i", false, "prompts to choose the validators to enable")
+	cmd.Flags().BoolVarP(&o.Overwrite, "overwrite", "", false, "overwrites the settings file if it already exists")
+	return cmd, o
+}
+
+// Validate validates the options
+func (o *Options) Validate() error {
+	err := o.BaseOptions.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "failed to validate options")
+	}
+	if o.Dir == "" {
+		o.Dir = "."
+	}
+	if o.SettingsFile == "" {
+		o.SettingsFile = filepath.Join(o.Dir, ".jx", "kube-test", "settings.yaml")
+	}
+	if !o.Overwrite {
+		exists, err := files.FileExists(o.SettingsFile)
+		if err != nil {
+			return errors.Wrapf(err, "failed to check if file exists %s", o.SettingsFile)
+		}
+		if exists {
+			return errors.Errorf("the settings file %s already exists. Use --overwrite to replace it", o.SettingsFile)
+		}
+	}
+
+	if o.Interactive && len(o.Tests) == 0 {
+		if o.Input == nil {
+			o.Input = inputfactory.NewInput(&o.BaseOptions)
+		}
+		o.Tests, err = o.Input.SelectNames(Validators, "Which validators do you want to enable:", false, "the validators to run on the resources, charts and kustomize overlays")
+		if err != nil {
+			return errors.Wrapf(err, "failed to pick the validators")
+		}
+	}
+	if len(o.Tests) == 0 {
+		o.Tests = DefaultValidators
+	}
+	for _, t := range o.Tests {
+		if stringhelpers.StringArrayIndex(Validators, t) < 0 {
+			return options.InvalidOption("tests", t, Validators)
+		}
+	}
+	return nil
+}
+
+// Run implements the command
+func (o *Options) Run() error {
+	err := o.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "failed to validate")
+	}
+
+	o.Settings, err = o.CreateSettings()
+	if err != nil {
+		return errors.Wrapf(err, "failed to create settings")
+	}
+
+	data, err := o.ToYAML(o.Settings)
+	if err != nil {
+		return errors.Wrapf(err, "failed to convert the settings to YAML")
+	}
+	dir := filepath.Dir(o.SettingsFile)
+	err = os.MkdirAll(dir, files.DefaultDirWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create dir %s", dir)
+	}
+	err = ioutil.WriteFile(o.SettingsFile, data, files.DefaultFileWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save file %s", o.SettingsFile)
+	}
+	log.Logger().Infof("created %s with %s rules", info(o.SettingsFile), info(len(o.Settings.Spec.Rules)))
+	return nil
+}
+
+// CreateSettings creates the settings using the config-root or charts dir found in the same way as the run command
+// plus any kustomize overlays and values files for variants of the charts
+func (o *Options) CreateSettings() (*v1alpha1.KubeTest, error) {
+	o.ruleComments = nil
+	answer := &v1alpha1.KubeTest{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.APIVersion,
+			Kind:       v1alpha1.KindKubeTest,
+		},
+	}
+
+	addRule := func(comment string, rule v1alpha1.Rule) {
+		o.ruleComments = append(o.ruleComments, comment)
+		rule.Tests = o.createTests()
+		answer.Spec.Rules = append(answer.Spec.Rules, rule)
+	}
+
+	sourceDir, chartsDir, err := run.FindDefaultSources(o.Dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the default source dirs")
+	}
+	if sourceDir != "" {
+		addRule(fmt.Sprintf("tests the kubernetes resources in %s", o.relativePath(sourceDir)), v1alpha1.Rule{
+			Resources: &v1alpha1.Source{
+				Dir: o.relativePath(sourceDir),
+			},
+		})
+	}
+
+	if chartsDir != "" {
+		chartDirs, err := run.FindChartDirs(chartsDir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to find charts in dir %s", chartsDir)
+		}
+		variants := map[string][]v1alpha1.ChartCase{}
+		for _, d := range chartDirs {
+			variants[d], err = o.FindValuesVariants(d)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to find the values files of chart %s", d)
+			}
+		}
+
+		if !hasVariants(variants) {
+			addRule(fmt.Sprintf("tests the default values of the charts in %s", o.relativePath(chartsDir)), v1alpha1.Rule{
+				Charts: &v1alpha1.Charts{
+					Dir:     o.relativePath(chartsDir),
+					Recurse: true,
+				},
+			})
+		} else {
+			// the cases apply to every chart in a rule so lets use a rule for each chart
+			for _, d := range chartDirs {
+				comment := fmt.Sprintf("tests the default values of chart %s", o.relativePath(d))
+				if len(variants[d]) > 0 {
+					comment = fmt.Sprintf("tests chart %s with its default values and each of its values files", o.relativePath(d))
+				}
+				addRule(comment, v1alpha1.Rule{
+					Charts: &v1alpha1.Charts{
+						Dir:   o.relativePath(d),
+						Cases: variants[d],
+					},
+				})
+			}
+		}
+	}
+
+	kustomizations, err := o.FindKustomizations(sourceDir, chartsDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the kustomize overlays")
+	}
+	for i := range kustomizations {
+		k := kustomizations[i]
+		comment := fmt.Sprintf("builds and tests the kustomization in %s", k.Dir)
+		if len(k.Overlays) > 0 {
+			comment = fmt.Sprintf("builds and tests the kustomize overlays in %s", k.Dir)
+		}
+		addRule(comment, v1alpha1.Rule{
+			Kustomize: &k,
+		})
+	}
+
+	if len(answer.Spec.Rules) == 0 {
+		return nil, errors.Errorf("could not find a config-root or charts dir or any kustomize overlays in %s", o.Dir)
+	}
+	return answer, nil
+}
+
+// createTests creates the tests for a rule from the chosen validators
+func (o *Options) createTests() v1alpha1.Tests {
+	tests := v1alpha1.Tests{}
+	for _, name := range o.Tests {
+		switch name {
+		case "conftest":
+			tests.Conftest = &v1alpha1.Test{}
+		case "deprecations":
+			tests.Deprecations = &v1alpha1.DeprecationsTest{}
+		case "kubeconform":
+			tests.Kubeconform = &v1alpha1.KubeconformTest{
+				Strict:               true,
+				IgnoreMissingSchemas: true,
+			}
+		case "kubescore":
+			tests.Kubescore = &v1alpha1.Test{}
+		case "kubeval":
+			tests.Kubeval = &v1alpha1.Test{
+				Args: run.DefaultKubevalArgs,
+			}
+		case "polaris":
+			tests.Polaris = &v1alpha1.Test{}
+		case "references":
+			tests.References = &v1alpha1.ReferencesTest{}
+		case "rego":
+			tests.Rego = &v1alpha1.RegoTest{}
+		}
+	}
+	return tests
+}
+
+// FindValuesVariants finds the values files for variants of the chart such as values-prod.yaml or the chart testing
+// ci/*-values.yaml files
+func (o *Options) FindValuesVariants(chartDir string) ([]v1alpha1.ChartCase, error) {
+	var paths []string
+	for _, pattern := range []string{"values-*.yaml", "values.*.yaml", filepath.Join("ci", "*-values.yaml")} {
+		matches, err := filepath.Glob(filepath.Join(chartDir, pattern))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to find files matching %s in %s", pattern, chartDir)
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	var answer []v1alpha1.ChartCase
+	names := map[string]bool{}
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".yaml")
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "values-"), "values.")
+		name = strings.TrimSuffix(name, "-values")
+		if name == "" || name == "default-values" || names[name] {
+			name = strings.TrimSuffix(filepath.Base(path), ".yaml")
+		}
+		if names[name] {
+			continue
+		}
+		names[name] = true
+		answer = append(answer, v1alpha1.ChartCase{
+			Name:        name,
+			ValuesFiles: []string{o.relativePath(path)},
+		})
+	}
+	return answer, nil
+}
+
+// FindKustomizations finds the kustomize dirs which are not used by other kustomizations. Overlays in the same
+// parent dir are grouped together. The resources and charts dirs are ignored
+func (o *Options) FindKustomizations(ignoreDirs ...string) ([]v1alpha1.Kustomize, error) {
+	ignore := map[string]bool{}
+	for _, d := range ignoreDirs {
+		if d != "" {
+			ignore[filepath.Clean(d)] = true
+		}
+	}
+
+	var dirs []string
+	used := map[string]bool{}
+	err := filepath.Walk(o.Dir, func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !f.IsDir() {
+			return nil
+		}
+		name := f.Name()
+		if path != o.Dir && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || ignore[filepath.Clean(path)]) {
+			return filepath.SkipDir
+		}
+		for _, kf := range kustomizationFiles {
+			file := filepath.Join(path, kf)
+			exists, err := files.FileExists(file)
+			if err != nil {
+				return errors.Wrapf(err, "failed to check if file exists %s", file)
+			}
+			if !exists {
+				continue
+			}
+			k := &kustomization{}
+			err = yamls.LoadFile(file, k)
+			if err != nil {
+				return errors.Wrapf(err, "failed to load file %s", file)
+			}
+			dirs = append(dirs, filepath.Clean(path))
+			for _, r := range append(append(k.Resources, k.Bases...), k.Components...) {
+				used[filepath.Join(path, r)] = true
+			}
+			break
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find kustomizations in %s", o.Dir)
+	}
+
+	overlays := map[string][]string{}
+	var parents []string
+	for _, d := range dirs {
+		if used[d] {
+			continue
+		}
+		parent := filepath.Dir(d)
+		if overlays[parent] == nil {
+			parents = append(parents, parent)
+		}
+		overlays[parent] = append(overlays[parent], filepath.Base(d))
+	}
+
+	var answer []v1alpha1.Kustomize
+	for _, parent := range parents {
+		names := overlays[parent]
+		if len(names) == 1 {
+			answer = append(answer, v1alpha1.Kustomize{
+				Dir: o.relativePath(filepath.Join(parent, names[0])),
+			})
+			continue
+		}
+		sort.Strings(names)
+		answer = append(answer, v1alpha1.Kustomize{
+			Dir:      o.relativePath(parent),
+			Overlays: names,
+		})
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Dir < answer[j].Dir
+	})
+	return answer, nil
+}
+
+// ToYAML converts the settings to YAML with comments describing the rules and tests
+func (o *Options) ToYAML(settings *v1alpha1.KubeTest) ([]byte, error) {
+	data, err := yaml.Marshal(settings)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal settings to YAML")
+	}
+	doc := &yamlv3.Node{}
+	err = yamlv3.Unmarshal(data, doc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the settings YAML")
+	}
+	if len(doc.Content) == 0 {
+		return nil, errors.Errorf("no YAML generated for the settings")
+	}
+	root := doc.Content[0]
+	root.HeadComment = "the settings of the tests run by: jx kube test run\ncreated by: jx kube test init\n\nthe JSON Schema of this file can be displayed via: jx kube test schema"
+
+	// lets remove the empty metadata and failure policy
+	removeEmptyKey(root, "metadata")
+	spec := lookup(root, "spec")
+	if spec != nil {
+		removeEmptyKey(spec, "failurePolicy")
+	}
+
+	rules := lookup(root, "spec", "rules")
+	if rules != nil {
+		for i, rule := range rules.Content {
+			if i < len(o.ruleComments) && len(rule.Content) > 0 {
+				rule.Content[0].HeadComment = o.ruleComments[i]
+			}
+			tests := lookup(rule, "tests")
+			if tests == nil {
+				continue
+			}
+			for j := 0; j+1 < len(tests.Content); j += 2 {
+				key := tests.Content[j]
+				key.HeadComment = validatorDescriptions[key.Value]
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	encoder := yamlv3.NewEncoder(buf)
+	encoder.SetIndent(2)
+	err = encoder.Encode(doc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to encode the settings YAML")
+	}
+	err = encoder.Close()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to close the YAML encoder")
+	}
+	return buf.Bytes(), nil
+}
+
+// lookup returns the value of the given path of keys in the mapping node or nil if it does not exist
+func lookup(node *yamlv3.Node, keys ...string) *yamlv3.Node {
+	for _, key := range keys {
+		if node.Kind != yamlv3.MappingNode {
+			return nil
+		}
+		var next *yamlv3.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// removeEmptyKey removes the key from the mapping node if its value is empty
+func removeEmptyKey(node *yamlv3.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		value := node.Content[i+1]
+		if node.Content[i].Value != key {
+			continue
+		}
+		if len(value.Content) == 0 || (len(value.Content) == 2 && value.Content[0].Value == "creationTimestamp" && value.Content[1].Tag == "!!null") {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+		}
+		return
+	}
+}
+
+// relativePath returns the path relative to the current directory as the run command resolves the paths in the
+// settings file relative to the current directory rather than the --dir
+func (o *Options) relativePath(path string) string {
+	if filepath.IsAbs(path) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return filepath.ToSlash(path)
+		}
+		rel, err := filepath.Rel(cwd, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(path)
+		}
+		path = rel
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+func hasVariants(variants map[string][]v1alpha1.ChartCase) bool {
+	for _, cases := range variants {
+		if len(cases) > 0 {
+			return true
+		}
+	}
+	return false
+}
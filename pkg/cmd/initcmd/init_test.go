@@ -0,0 +1,162 @@
+package initcmd_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/initcmd"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/run"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/schema"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner/fakerunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/input/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitChartsAndKustomize(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	_, o := initcmd.NewCmdInit()
+	o.Dir = filepath.Join("test_data", "repo")
+	o.SettingsFile = filepath.Join(tmpDir, "settings.yaml")
+	o.Tests = []string{"kubeconform", "kubescore"}
+	err = o.Run()
+	require.NoError(t, err, "failed to run")
+
+	settings := loadSettings(t, o.SettingsFile)
+	expected := []v1alpha1.Rule{
+		{
+			Charts: &v1alpha1.Charts{
+				Dir: "test_data/repo/charts/myapp",
+				Cases: []v1alpha1.ChartCase{
+					{
+						Name:        "ha",
+						ValuesFiles: []string{"test_data/repo/charts/myapp/ci/ha-values.yaml"},
+					},
+					{
+						Name:        "prod",
+						ValuesFiles: []string{"test_data/repo/charts/myapp/values-prod.yaml"},
+					},
+				},
+			},
+		},
+		{
+			Charts: &v1alpha1.Charts{
+				Dir: "test_data/repo/charts/other",
+			},
+		},
+		{
+			Kustomize: &v1alpha1.Kustomize{
+				Dir:      "test_data/repo/apps/myapp/overlays",
+				Overlays: []string{"dev", "prod"},
+			},
+		},
+		{
+			Kustomize: &v1alpha1.Kustomize{
+				Dir: "test_data/repo/apps/tools",
+			},
+		},
+	}
+	require.Len(t, settings.Spec.Rules, len(expected), "rules")
+	for i := range expected {
+		rule := settings.Spec.Rules[i]
+		assert.Equal(t, expected[i].Charts, rule.Charts, "charts for rule %d", i)
+		assert.Equal(t, expected[i].Kustomize, rule.Kustomize, "kustomize for rule %d", i)
+		assert.Nil(t, rule.Resources, "resources for rule %d", i)
+
+		require.NotNil(t, rule.Tests.Kubeconform, "kubeconform test for rule %d", i)
+		assert.True(t, rule.Tests.Kubeconform.Strict, "kubeconform strict for rule %d", i)
+		assert.NotNil(t, rule.Tests.Kubescore, "kubescore test for rule %d", i)
+		assert.Nil(t, rule.Tests.Kubeval, "kubeval test for rule %d", i)
+	}
+
+	data, err := ioutil.ReadFile(o.SettingsFile)
+	require.NoError(t, err, "failed to load %s", o.SettingsFile)
+	text := string(data)
+	t.Logf("generated settings:\n%s\n", text)
+	assert.Contains(t, text, "# tests chart test_data/repo/charts/myapp with its default values and each of its values files", "rule comment")
+	assert.Contains(t, text, "# builds and tests the kustomize overlays in test_data/repo/apps/myapp/overlays", "rule comment")
+	assert.Contains(t, text, "# checks the resources follow security and reliability best practices\n        kubescore: {}", "test comment")
+}
+
+func TestInitConfigRoot(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	_, o := initcmd.NewCmdInit()
+	o.Dir = filepath.Join("test_data", "gitops")
+	o.SettingsFile = filepath.Join(tmpDir, "settings.yaml")
+	err = o.Run()
+	require.NoError(t, err, "failed to run")
+
+	settings := loadSettings(t, o.SettingsFile)
+	require.Len(t, settings.Spec.Rules, 1, "rules")
+	rule := settings.Spec.Rules[0]
+	require.NotNil(t, rule.Resources, "resources")
+	assert.Equal(t, "test_data/gitops/config-root", rule.Resources.Dir, "resources dir")
+
+	require.NotNil(t, rule.Tests.Kubeval, "default kubeval test")
+	assert.Equal(t, run.DefaultKubevalArgs, rule.Tests.Kubeval.Args, "kubeval args")
+	assert.NotNil(t, rule.Tests.Deprecations, "default deprecations test")
+
+	// lets check the run command finds the resources using the generated settings
+	runner := &fakerunner.FakeRunner{
+		CommandRunner: func(c *cmdrunner.Command) (string, error) {
+			return "[]", nil
+		},
+	}
+	_, ro := run.NewCmdRun()
+	ro.NoCache = true
+	ro.Dir = o.Dir
+	ro.SettingsFile = o.SettingsFile
+	ro.CommandRunner = runner.Run
+	ro.KubevalPlugin.Binary = "kubeval"
+	err = ro.Run()
+	require.NoError(t, err, "failed to run the generated settings")
+	require.NotEmpty(t, ro.Results(), "results of the generated settings")
+	assert.Equal(t, "foo", ro.Results()[0].Name, "resource tested with the generated settings")
+
+	_, o = initcmd.NewCmdInit()
+	o.Dir = filepath.Join("test_data", "gitops")
+	o.SettingsFile = filepath.Join(tmpDir, "settings.yaml")
+	err = o.Run()
+	require.Error(t, err, "should fail if the settings file exists")
+	t.Logf("got expected error: %s", err.Error())
+}
+
+func TestInitInteractive(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	_, o := initcmd.NewCmdInit()
+	o.Dir = filepath.Join("test_data", "gitops")
+	o.SettingsFile = filepath.Join(tmpDir, "settings.yaml")
+	o.Interactive = true
+	o.Input = &fake.FakeInput{
+		Values: map[string]string{
+			"Which validators do you want to enable:": "polaris",
+		},
+	}
+	err = o.Run()
+	require.NoError(t, err, "failed to run")
+
+	settings := loadSettings(t, o.SettingsFile)
+	require.Len(t, settings.Spec.Rules, 1, "rules")
+	tests := settings.Spec.Rules[0].Tests
+	assert.NotNil(t, tests.Polaris, "polaris test")
+	assert.Nil(t, tests.Deprecations, "deprecations test")
+	assert.Nil(t, tests.Kubeval, "kubeval test")
+}
+
+func loadSettings(t *testing.T, path string) *v1alpha1.KubeTest {
+	settings := &v1alpha1.KubeTest{}
+	err := schema.LoadFile(path, settings)
+	require.NoError(t, err, "failed to load the generated settings %s", path)
+	assert.Equal(t, v1alpha1.APIVersion, settings.APIVersion, "apiVersion")
+	assert.Equal(t, v1alpha1.KindKubeTest, settings.Kind, "kind")
+	return settings
+}
@@ -0,0 +1,148 @@
+package list
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/run"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cobras/helper"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cobras/templates"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/options"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/table"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// FormatTable displays the targets as a table
+	FormatTable = "table"
+
+	// FormatJSON displays the targets as JSON
+	FormatJSON = "json"
+
+	// workDirPlaceholder the work dir displayed in the output dirs of generated resources if no work dir is specified
+	workDirPlaceholder = "${WORK_DIR}"
+)
+
+var (
+	cmdLong = templates.LongDesc(`
+		Lists the charts, values, overlays and resource dirs which would be tested along with the tools, versions and arguments without running any tests
+`)
+
+	cmdExample = templates.Examples(`
+		# lists what would be tested using the .jx/kube-test/settings.yaml file
+		jx kube test list
+
+		# lists what would be tested as JSON
+		jx kube test list --format json
+
+		# lists what would be tested on the charts which changed since the main branch
+		jx kube test list --changed-since origin/main
+	`)
+
+	// Formats the supported output formats
+	Formats = []string{FormatTable, FormatJSON}
+)
+
+// Options the options for the command
+type Options struct {
+	run.Options
+
+	Format  string
+	Targets []run.Target
+	Out     io.Writer
+}
+
+// NewCmdList creates a command object for the command
+func NewCmdList() (*cobra.Command, *Options) {
+	o := &Options{}
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "Lists what would be tested without running any tests",
+		Long:    cmdLong,
+		Example: cmdExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := o.Run()
+			helper.CheckErr(err)
+		},
+	}
+	o.Options.AddTargetFlags(cmd)
+	o.Options.AddChangedSinceFlags(cmd)
+
+	cmd.Flags().StringVarP(&o.Format, "format", "", FormatTable, "the format to display the targets: table or json")
+	return cmd, o
+}
+
+// Run implements the command
+func (o *Options) Run() error {
+	if o.Format == "" {
+		o.Format = FormatTable
+	}
+	if o.Format != FormatTable && o.Format != FormatJSON {
+		return options.InvalidOption("format", o.Format, Formats)
+	}
+	if o.WorkDir == "" {
+		// lets avoid creating a temporary dir as nothing is generated
+		o.WorkDir = workDirPlaceholder
+	}
+	// lets avoid creating the cache dir as no tools are run
+	o.NoCache = true
+	err := o.Options.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "failed to validate")
+	}
+
+	o.Targets, err = o.FindTargets()
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the targets")
+	}
+	if o.Out == nil {
+		o.Out = os.Stdout
+	}
+	if o.Format == FormatJSON {
+		data, err := json.MarshalIndent(o.Targets, "", "  ")
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal the targets to JSON")
+		}
+		_, err = fmt.Fprintln(o.Out, string(data))
+		if err != nil {
+			return errors.Wrapf(err, "failed to write the targets")
+		}
+		return nil
+	}
+
+	t := table.CreateTable(o.Out)
+	t.AddRow("RULE", "TARGET", "RELEASE", "KUBERNETES", "TOOL", "VERSION", "ARGS")
+	for i := range o.Targets {
+		target := &o.Targets[i]
+		name := target.Chart
+		if name == "" {
+			name = target.SourceDir
+		}
+		if name == "" {
+			name = target.Description
+		}
+		rule := ""
+		if target.Rule >= 0 {
+			rule = strconv.Itoa(target.Rule)
+		}
+		if len(target.Tools) == 0 {
+			t.AddRow(rule, name, target.Release, target.KubernetesVersion, "", "", "")
+			continue
+		}
+		for _, tool := range target.Tools {
+			t.AddRow(rule, name, target.Release, target.KubernetesVersion, tool.Name, tool.Version, strings.Join(tool.Args, " "))
+			if len(tool.ReportArgs) > 0 {
+				t.AddRow(rule, name, target.Release, target.KubernetesVersion, tool.Name+" report", tool.Version, strings.Join(tool.ReportArgs, " "))
+			}
+		}
+	}
+	t.Render()
+	return nil
+}
@@ -0,0 +1,159 @@
+package list_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/list"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/run"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestList(t *testing.T) {
+	chartsDir := filepath.Join("test_data", "charts")
+	chartDir := filepath.Join(chartsDir, "myapp")
+	resourcesDir := filepath.Join("test_data", "resources")
+
+	newOptions := func(format string) *list.Options {
+		_, o := list.NewCmdList()
+		o.Format = format
+		o.CommandRunner = func(c *cmdrunner.Command) (string, error) {
+			return "", errors.Errorf("should not run command %s", c.CLI())
+		}
+		o.Settings = &v1alpha1.KubeTest{
+			Spec: v1alpha1.KubeTestSpec{
+				KubernetesVersions: []v1alpha1.KubernetesVersion{
+					{Version: "1.21.0"},
+					{Version: "1.22.0"},
+				},
+				Duplicates: &v1alpha1.DuplicatesTest{},
+				Rules: []v1alpha1.Rule{
+					{
+						Charts: &v1alpha1.Charts{
+							Dir:     chartsDir,
+							Recurse: true,
+						},
+						Tests: v1alpha1.Tests{
+							Kubeconform: &v1alpha1.KubeconformTest{
+								Test: v1alpha1.Test{
+									Version: "0.4.12",
+								},
+								Strict: true,
+							},
+							Deprecations: &v1alpha1.DeprecationsTest{},
+						},
+					},
+					{
+						Resources: &v1alpha1.Source{
+							Dir: resourcesDir,
+						},
+						Tests: v1alpha1.Tests{
+							Polaris: &v1alpha1.Test{
+								Args: []string{"--only-show-failed-tests"},
+							},
+						},
+					},
+				},
+			},
+		}
+		return o
+	}
+
+	var out bytes.Buffer
+	o := newOptions(list.FormatJSON)
+	o.Out = &out
+	kubeconformVersion := o.KubeconformPlugin.Version
+	err := o.Run()
+	require.NoError(t, err, "failed to run")
+	assert.Nil(t, o.Cache, "should not use the cache")
+	assert.Empty(t, o.KubeconformPlugin.Binary, "should not leave the kubeconform binary set")
+	assert.Equal(t, kubeconformVersion, o.KubeconformPlugin.Version, "should restore the kubeconform version")
+
+	var targets []run.Target
+	err = json.Unmarshal(out.Bytes(), &targets)
+	require.NoError(t, err, "failed to parse JSON output %s", out.String())
+	assert.Equal(t, o.Targets, targets, "JSON output")
+	require.Len(t, targets, 7, "targets")
+
+	var names []string
+	for _, target := range targets {
+		names = append(names, target.Chart+"/"+target.Release+"/"+target.SourceDir+"/"+target.KubernetesVersion)
+	}
+	assert.Equal(t, []string{
+		chartDir + "/default-values//1.21.0",
+		chartDir + "/default-values//1.22.0",
+		chartDir + "/production//1.21.0",
+		chartDir + "/production//1.22.0",
+		"//" + resourcesDir + "/1.21.0",
+		"//" + resourcesDir + "/1.22.0",
+		"///",
+	}, names, "targets")
+
+	target := targets[0]
+	outDir := filepath.Join("${WORK_DIR}", chartDir, "default-values", "kubernetes-1.21.0")
+	assert.Equal(t, outDir, target.OutputDir, "chart output dir")
+	require.Len(t, target.Tools, 2, "chart tools")
+	assert.Equal(t, run.TargetTool{
+		Name:    "kubeconform",
+		Version: "0.4.12",
+		Args:    []string{"-verbose", "-strict", "--kubernetes-version", "1.21.0", "--output", "json", outDir},
+	}, target.Tools[0], "kubeconform tool")
+	assert.Equal(t, run.TargetTool{Name: "deprecations"}, target.Tools[1], "deprecations tool")
+
+	target = targets[5]
+	require.Len(t, target.Tools, 1, "resources tools")
+	tool := target.Tools[0]
+	assert.Equal(t, "polaris", tool.Name, "resources tool")
+	assert.NotEmpty(t, tool.Version, "polaris version")
+	assert.Equal(t, []string{"audit", "--audit-path", resourcesDir, "--only-show-failed-tests", "--format", "json"}, tool.Args, "polaris args")
+	assert.Empty(t, tool.ReportArgs, "polaris report args")
+
+	target = targets[6]
+	assert.Equal(t, -1, target.Rule, "duplicates rule")
+	assert.Equal(t, "resources in "+resourcesDir, target.Description, "duplicates description")
+	assert.Equal(t, []run.TargetTool{{Name: "duplicates"}}, target.Tools, "duplicates tools")
+
+	out.Reset()
+	o = newOptions(list.FormatTable)
+	o.Out = &out
+	err = o.Run()
+	require.NoError(t, err, "failed to run")
+
+	text := out.String()
+	t.Logf("%s\n", text)
+	assert.Contains(t, text, "KUBERNETES", "table header")
+	assert.Contains(t, text, "-verbose -strict --kubernetes-version 1.22.0", "table kubeconform args")
+
+	// the tools are run a second time to save their reports in the yaml format
+	out.Reset()
+	o = newOptions(list.FormatJSON)
+	o.Out = &out
+	o.Settings.Spec.OutputDir = "reports"
+	o.Settings.Spec.Format = "yaml"
+	err = o.Run()
+	require.NoError(t, err, "failed to run")
+	require.Len(t, o.Targets, 7, "targets")
+	tool = o.Targets[5].Tools[0]
+	assert.Equal(t, []string{"audit", "--audit-path", resourcesDir, "--only-show-failed-tests", "--format", "json"}, tool.Args, "polaris args")
+	assert.Equal(t, []string{"audit", "--audit-path", resourcesDir, "--only-show-failed-tests", "--format", "yaml"}, tool.ReportArgs, "polaris report args")
+
+	o = newOptions("yaml")
+	err = o.Run()
+	require.Error(t, err, "invalid format")
+}
+
+func TestListFlags(t *testing.T) {
+	cmd, _ := list.NewCmdList()
+	for _, name := range []string{"dir", "chart-dir", "source-dir", "recurse", "settings", "work-dir", "kubeval-version", "changed-since"} {
+		assert.NotNil(t, cmd.Flags().Lookup(name), "should have flag --%s", name)
+	}
+	for _, name := range []string{"parallelism", "fail-on", "no-cache", "cache-dir", "cache-max-size", "baseline"} {
+		assert.Nil(t, cmd.Flags().Lookup(name), "should not have flag --%s which is only used when running tests", name)
+	}
+}
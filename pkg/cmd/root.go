@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/baseline"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/initcmd"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/list"
 	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/run"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/schema"
 	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/version"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/cobras"
 	"github.com/jenkins-x/jx-logging/v3/pkg/log"
@@ -20,7 +24,11 @@ func Main() *cobra.Command {
 			}
 		},
 	}
+	cmd.AddCommand(cobras.SplitCommand(baseline.NewCmdBaseline()))
+	cmd.AddCommand(cobras.SplitCommand(initcmd.NewCmdInit()))
+	cmd.AddCommand(cobras.SplitCommand(list.NewCmdList()))
 	cmd.AddCommand(cobras.SplitCommand(run.NewCmdRun()))
+	cmd.AddCommand(cobras.SplitCommand(schema.NewCmdSchema()))
 	cmd.AddCommand(cobras.SplitCommand(version.NewCmdVersion()))
 	return cmd
 }
@@ -0,0 +1,31 @@
+package run
+
+import (
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/assertions"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/pkg/errors"
+)
+
+// validateAssertions compiles the assertions of each rule so that invalid expressions fail before running any tests
+func (o *Options) validateAssertions() error {
+	for i := range o.Settings.Spec.Rules {
+		_, err := assertions.Compile(o.Settings.Spec.Rules[i].Tests.Assertions)
+		if err != nil {
+			return errors.Wrapf(err, "invalid spec.rules[%d].tests.assertions", i)
+		}
+	}
+	return nil
+}
+
+// checkAssertions evaluates the inline CEL assertions against the resources
+func (o *Options) checkAssertions(co *results.ResourceLocation, list []v1alpha1.Assertion) error {
+	c, err := assertions.Compile(list)
+	if err != nil {
+		return errors.Wrapf(err, "failed to compile the assertions")
+	}
+	return o.runBuiltinCheck(assertions.ToolName, co, []string{co.OutputDir}, "failed assertions", func(resources []*manifests.Manifest) ([]*results.Result, error) {
+		return c.Check(resources, co), nil
+	})
+}
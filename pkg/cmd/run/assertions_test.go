@@ -0,0 +1,41 @@
+package run_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/run"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssertions(t *testing.T) {
+	newOptions := func(expression string) *run.Options {
+		return newTestOptions(t, nil, resourcesRule(filepath.Join("testdata_fixtures", "suppressed"), v1alpha1.Tests{
+			Assertions: []v1alpha1.Assertion{
+				{
+					Name:       "team-label",
+					Kind:       "Deployment",
+					Expression: expression,
+					Message:    "deployments must have a team label",
+				},
+			},
+		}))
+	}
+
+	o := newOptions("has(object.metadata.labels) && has(object.metadata.labels.team)")
+	err := o.Run()
+	require.Error(t, err, "should fail on the failed assertion")
+	require.Len(t, o.Results(), 1, "results")
+	r := o.Results()[0]
+	assert.Equal(t, "assertions", r.Tool, "result tool")
+	assert.Equal(t, "team-label", r.CheckID, "result check")
+	assert.Equal(t, results.StatusFailed, r.Status, "result status")
+
+	o = newOptions("has(object.metadata.labels.team")
+	err = o.Validate()
+	require.Error(t, err, "should fail to validate an invalid expression")
+	assert.Contains(t, err.Error(), "spec.rules[0].tests.assertions", "error message")
+}
@@ -0,0 +1,122 @@
+package run
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/baseline"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+)
+
+// waivers the state of applying the waivers in the baseline file to the results of a run
+type waivers struct {
+	lock    sync.Mutex
+	active  map[string]*baseline.Waiver
+	expired []*baseline.Waiver
+	used    map[string]bool
+	tested  map[string]bool
+	waived  int
+}
+
+func newWaivers(b *baseline.Baseline, now time.Time) *waivers {
+	w := &waivers{
+		active: map[string]*baseline.Waiver{},
+		used:   map[string]bool{},
+		tested: map[string]bool{},
+	}
+	for i := range b.Waivers {
+		waiver := &b.Waivers[i]
+		if waiver.Expired(now) {
+			w.expired = append(w.expired, waiver)
+			continue
+		}
+		w.active[waiver.Key()] = waiver
+	}
+	return w
+}
+
+// BaselineChart returns the chart of the location used in the baseline file
+func (o *Options) BaselineChart(co *results.ResourceLocation) string {
+	if co == nil || co.Chart == "" {
+		return ""
+	}
+	return filepath.ToSlash(o.relativePath(co.Chart))
+}
+
+// BaselineSourceDir returns the resource dir, kustomize overlay or helmfile dir of the location used in the baseline
+// file if the resources were not generated from a chart
+func (o *Options) BaselineSourceDir(co *results.ResourceLocation) string {
+	if co == nil || co.Chart != "" || co.SourceDir == "" {
+		return ""
+	}
+	return filepath.ToSlash(o.relativePath(co.SourceDir))
+}
+
+// applyWaivers marks any failed results which are waived in the baseline file
+func (o *Options) applyWaivers(outcome *TestOutcome) {
+	w := o.waivers
+	if w == nil {
+		return
+	}
+	chart := o.BaselineChart(outcome.Location)
+	sourceDir := o.BaselineSourceDir(outcome.Location)
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.tested[baseline.Key(outcome.Tool, "", "", "", "", chart, sourceDir)] = true
+	waived := 0
+	for _, r := range outcome.Results {
+		if !r.Failed() || !baseline.CanWaive(r) {
+			continue
+		}
+		key := baseline.ResultKey(r, chart, sourceDir)
+		if w.active[key] == nil {
+			continue
+		}
+		w.used[key] = true
+		r.Status = results.StatusWaived
+		waived++
+	}
+	w.waived += waived
+}
+
+// StaleWaivers returns the waivers which did not match any findings of tools which ran on the waiver's chart or dir
+func (o *Options) StaleWaivers() []baseline.Waiver {
+	w := o.waivers
+	if w == nil {
+		return nil
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	b := &baseline.Baseline{}
+	for key, waiver := range w.active {
+		// lets only report waivers for tools which ran on the chart or dir in case we only tested some of them
+		if w.used[key] || !w.tested[baseline.Key(waiver.Tool, "", "", "", "", waiver.Chart, waiver.SourceDir)] {
+			continue
+		}
+		b.Waivers = append(b.Waivers, *waiver)
+	}
+	b.Sort()
+	return b.Waivers
+}
+
+// reportWaivers logs the expired waivers and the stale waivers which no longer match any findings
+func (o *Options) reportWaivers() {
+	w := o.waivers
+	if w == nil {
+		return
+	}
+	for _, waiver := range w.expired {
+		log.Logger().Warnf("the waiver for %s expired on %s", waiver.String(), waiver.Expires)
+	}
+
+	stale := o.StaleWaivers()
+	for i := range stale {
+		log.Logger().Warnf("stale waiver: %s no longer fails so can be removed from %s", stale[i].String(), o.BaselineFile)
+	}
+	log.Logger().Infof("waived %s findings using %s: %s stale and %s expired waivers", info(w.waived), o.BaselineFile, info(len(stale)), info(len(w.expired)))
+}
@@ -0,0 +1,121 @@
+package run_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/baseline"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner/fakerunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaivedFailures(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	resourcesDir := filepath.Join("testdata_fixtures", "suppressed")
+	policyDir := filepath.Join("testdata_fixtures", "policy")
+	warnDir := filepath.Join(tmpDir, "policy")
+	err = os.MkdirAll(warnDir, files.DefaultDirWritePermissions)
+	require.NoError(t, err, "failed to create dir %s", warnDir)
+	err = ioutil.WriteFile(filepath.Join(warnDir, "warn.rego"), []byte("package main\n\nwarn[msg] {\n  input.kind == \"Deployment\"\n  msg := \"deployments should have a pod disruption budget\"\n}\n"), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to save the warn policy")
+
+	baselineFile := filepath.Join(tmpDir, "baseline.yaml")
+	waiver := func(tool, checkID string) baseline.Waiver {
+		return baseline.Waiver{
+			Tool:      tool,
+			CheckID:   checkID,
+			Kind:      "Deployment",
+			Name:      "myapp",
+			Namespace: "jx",
+			SourceDir: "testdata_fixtures/suppressed",
+		}
+	}
+	err = baseline.SaveFile(baselineFile, &baseline.Baseline{
+		Waivers: []baseline.Waiver{
+			waiver(results.ToolKubeScore, "container-resources"),
+			waiver("rego", "data.main.deny"),
+		},
+	})
+	require.NoError(t, err, "failed to save baseline")
+
+	// the critical kube-score finding and the rego deny are waived leaving a warning from each tool
+	kubeScoreOutput := `[{"type_meta": {"kind": "Deployment"}, "object_meta": {"name": "myapp", "namespace": "jx"}, "checks": [{"check": {"id": "container-resources"}, "grade": 1}, {"check": {"id": "pod-probes"}, "grade": 5}]}]`
+	testCases := []struct {
+		name        string
+		tests       v1alpha1.Tests
+		failOn      string
+		waived      results.Severity
+		expectError bool
+	}{
+		{
+			name: "kube-score",
+			tests: v1alpha1.Tests{
+				Kubescore: &v1alpha1.Test{},
+			},
+			waived: results.SeverityCritical,
+		},
+		{
+			name: "kube-score with warning threshold",
+			tests: v1alpha1.Tests{
+				Kubescore: &v1alpha1.Test{},
+			},
+			waived:      results.SeverityCritical,
+			failOn:      "warning",
+			expectError: true,
+		},
+		{
+			name: "rego",
+			tests: v1alpha1.Tests{
+				Rego: &v1alpha1.RegoTest{
+					Policies: []string{policyDir, warnDir},
+				},
+			},
+			waived: results.SeverityError,
+		},
+		{
+			name: "rego with warning threshold",
+			tests: v1alpha1.Tests{
+				Rego: &v1alpha1.RegoTest{
+					Policies: []string{policyDir, warnDir},
+				},
+			},
+			waived:      results.SeverityError,
+			failOn:      "warning",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		runner := &fakerunner.FakeRunner{
+			CommandRunner: func(c *cmdrunner.Command) (string, error) {
+				return kubeScoreOutput, errors.Errorf("kube-score failed")
+			},
+		}
+
+		o := newTestOptions(t, runner.Run, resourcesRule(resourcesDir, tc.tests))
+		o.BaselineFile = baselineFile
+		o.Settings.Spec.FailOn = tc.failOn
+		err := o.Run()
+		if tc.expectError {
+			require.Error(t, err, "should fail on the remaining warning for %s", tc.name)
+		} else {
+			require.NoError(t, err, "should not fail on the remaining warning for %s", tc.name)
+		}
+
+		var statuses []string
+		for _, r := range o.Results() {
+			statuses = append(statuses, string(r.Severity)+" "+string(r.Status))
+		}
+		assert.ElementsMatch(t, []string{string(tc.waived) + " waived", "warning failed"}, statuses, "results for %s", tc.name)
+	}
+}
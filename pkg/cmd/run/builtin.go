@@ -0,0 +1,66 @@
+package run
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/pkg/errors"
+)
+
+// builtinCheck checks the resources in process rather than running a tool
+type builtinCheck func(resources []*manifests.Manifest) ([]*results.Result, error)
+
+// runBuiltinCheck loads the resources in the dirs, runs the built in check on them and records the outcome. The test
+// fails if any of the findings have at least the error severity using the description of the failures in the error
+// such as "policy violations"
+func (o *Options) runBuiltinCheck(name string, co *results.ResourceLocation, dirs []string, failures string, check builtinCheck) error {
+	o.logger().Debugf("%s is verifying %s...", name, co.Description)
+
+	start := time.Now()
+	resources, err := loadResources(dirs)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load resources")
+	}
+	items, err := check(resources)
+	if err != nil {
+		return errors.Wrapf(err, "failed to run %s on %s", name, co.Description)
+	}
+	duration := time.Since(start)
+	results.Suppress(items, resources)
+
+	for _, r := range items {
+		r.Duration = duration
+	}
+	var testErr error
+	failed := results.FailuresAtLeast(items, results.SeverityError)
+	if len(failed) > 0 {
+		testErr = errors.Errorf("found %d %s", len(failed), failures)
+	}
+
+	if o.Settings.Spec.OutputDir == "" {
+		o.logResults(name, co, items)
+	}
+	return o.addOutcome(name, co, items, testErr, "")
+}
+
+// loadResources loads the resources in the dirs ignoring the files in nested dirs which have already been loaded
+func loadResources(dirs []string) ([]*manifests.Manifest, error) {
+	var answer []*manifests.Manifest
+	loaded := map[string]bool{}
+	for _, dir := range dirs {
+		resources, err := manifests.LoadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range resources {
+			key := fmt.Sprintf("%s:%d", m.File, m.Index)
+			if !loaded[key] {
+				loaded[key] = true
+				answer = append(answer, m)
+			}
+		}
+	}
+	return answer, nil
+}
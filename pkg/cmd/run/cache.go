@@ -0,0 +1,190 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cache"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+)
+
+const (
+	// cacheOutputDir the placeholder used for the output dir in cached output so the output can be reused in a
+	// different work dir
+	cacheOutputDir = "${OUTPUT_DIR}"
+
+	// defaultConftestPolicyDir the policy dir conftest uses if none is specified
+	defaultConftestPolicyDir = "policy"
+)
+
+// toolPlugin returns the plugin for the given tool
+func (o *Options) toolPlugin(name string) *BinaryPlugin {
+	switch name {
+	case results.ToolConftest:
+		return &o.ConftestPlugin
+	case results.ToolKubeconform:
+		return &o.KubeconformPlugin
+	case results.ToolKubeScore:
+		return &o.KubeScorePlugin
+	case results.ToolKubeval:
+		return &o.KubevalPlugin
+	case results.ToolPolaris:
+		return &o.PolarisPlugin
+	}
+	return nil
+}
+
+// cacheKey returns the key of the cached output of the tool command on the location or an empty string if caching
+// is disabled. The key is a hash of the resources, the tool version and arguments, the report format and the content
+// of any policy files or dirs referenced by the arguments. A binary specified by the user is identified by its path,
+// size and modified time rather than the version which may not match it
+func (o *Options) cacheKey(name string, co *results.ResourceLocation, c *cmdrunner.Command) string {
+	if o.Cache == nil || co.OutputDir == "" {
+		return ""
+	}
+	h := cache.NewHasher()
+	version := ""
+	plugin := o.toolPlugin(name)
+	if plugin != nil {
+		version = plugin.Version
+		if !plugin.downloaded {
+			var err error
+			version, err = binaryVersion(c.Name)
+			if err != nil {
+				o.logger().Debugf("failed to find the %s binary %s so not using the cache: %s", name, c.Name, err.Error())
+				return ""
+			}
+		}
+	}
+	h.AddString(name, version, filepath.Base(c.Name), o.Settings.Spec.Format, strconv.FormatBool(o.Settings.Spec.OutputDir != ""))
+	for _, arg := range c.Args {
+		h.AddString(strings.ReplaceAll(arg, co.OutputDir, cacheOutputDir))
+	}
+
+	err := h.AddPath(co.OutputDir)
+	if err != nil {
+		o.logger().Warnf("failed to hash the resources in %s so not using the cache: %s", co.OutputDir, err.Error())
+		return ""
+	}
+	for _, path := range policyPaths(name, co, c.Args) {
+		h.AddString(path)
+		err = h.AddPath(path)
+		if err != nil {
+			o.logger().Warnf("failed to hash %s so not using the cache: %s", path, err.Error())
+			return ""
+		}
+	}
+	return h.Key()
+}
+
+// binaryVersion returns the path, size and modified time of the binary so that the cache is not used if it changes
+func binaryVersion(binary string) (string, error) {
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return "", err
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %d %d", path, info.Size(), info.ModTime().UnixNano()), nil
+}
+
+// policyPaths returns the files or dirs outside of the output dir which are passed as flag values such as conftest
+// policies or a polaris config file as changes to them change the results
+func policyPaths(name string, co *results.ResourceLocation, args []string) []string {
+	var answer []string
+	hasPolicy := false
+	for i, arg := range args {
+		value := ""
+		if strings.HasPrefix(arg, "-") {
+			idx := strings.Index(arg, "=")
+			if idx < 0 {
+				continue
+			}
+			value = arg[idx+1:]
+		} else if i > 0 && strings.HasPrefix(args[i-1], "-") && !strings.Contains(args[i-1], "=") {
+			value = arg
+		} else {
+			continue
+		}
+		if value == "" || strings.Contains(value, co.OutputDir) {
+			continue
+		}
+		_, err := os.Stat(value)
+		if err != nil {
+			continue
+		}
+		if name == results.ToolConftest && (i > 0 && (args[i-1] == "-p" || args[i-1] == "--policy") || strings.HasPrefix(arg, "--policy=")) {
+			hasPolicy = true
+		}
+		answer = append(answer, value)
+	}
+	if name == results.ToolConftest && !hasPolicy {
+		_, err := os.Stat(defaultConftestPolicyDir)
+		if err == nil {
+			answer = append(answer, defaultConftestPolicyDir)
+		}
+	}
+	return answer
+}
+
+// getCacheEntry returns the cached output for the key or nil if there is none
+func (o *Options) getCacheEntry(key string, co *results.ResourceLocation) *cache.Entry {
+	if key == "" {
+		return nil
+	}
+	entry, err := o.Cache.Get(key)
+	if err != nil {
+		o.logger().Warnf("failed to read the cache: %s", err.Error())
+		return nil
+	}
+	if entry == nil {
+		return nil
+	}
+	entry.Output = strings.ReplaceAll(entry.Output, cacheOutputDir, co.OutputDir)
+	entry.Report = strings.ReplaceAll(entry.Report, cacheOutputDir, co.OutputDir)
+	entry.Error = strings.ReplaceAll(entry.Error, cacheOutputDir, co.OutputDir)
+	return entry
+}
+
+// putCacheEntry caches the output of the tool for the key
+func (o *Options) putCacheEntry(key string, co *results.ResourceLocation, text, reportText string, testErr error) {
+	if key == "" {
+		return
+	}
+	entry := &cache.Entry{
+		Output: strings.ReplaceAll(text, co.OutputDir, cacheOutputDir),
+		Report: strings.ReplaceAll(reportText, co.OutputDir, cacheOutputDir),
+	}
+	if testErr != nil {
+		entry.Error = strings.ReplaceAll(testErr.Error(), co.OutputDir, cacheOutputDir)
+	}
+	err := o.Cache.Put(key, entry)
+	if err != nil {
+		o.logger().Warnf("failed to save the cache: %s", err.Error())
+	}
+}
+
+// isToolError returns true if the tool failed without reporting the results of any checks, such as when a schema
+// could not be downloaded or the binary is missing, as the failure may not happen again so should not be cached
+func isToolError(testErr error, items []*results.Result) bool {
+	if testErr == nil {
+		return false
+	}
+	for _, r := range items {
+		if r.CheckID != results.CheckToolError {
+			return false
+		}
+	}
+	return true
+}
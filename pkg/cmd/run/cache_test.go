@@ -0,0 +1,110 @@
+package run_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/run"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner/fakerunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	resourcesDir := filepath.Join(tmpDir, "config-root")
+	err = files.CopyDirOverwrite(filepath.Join("testdata_fixtures", "resources", "config-root"), resourcesDir)
+	require.NoError(t, err, "failed to copy resources")
+	cacheDir := filepath.Join(tmpDir, "cache")
+	serviceFile := filepath.Join(resourcesDir, "namespaces", "jx", "service.yaml")
+
+	// the binary is specified by the user so its version is not known
+	kubevalBinary := filepath.Join(tmpDir, "kubeval")
+	err = ioutil.WriteFile(kubevalBinary, []byte("#!/bin/sh\n"), 0755)
+	require.NoError(t, err, "failed to write %s", kubevalBinary)
+
+	runTests := func() (*fakerunner.FakeRunner, *run.Options) {
+		runner := &fakerunner.FakeRunner{
+			CommandRunner: func(c *cmdrunner.Command) (string, error) {
+				return `[{"filename": "` + serviceFile + `", "kind": "Service", "status": "invalid", "errors": ["bad port"]}]`, errors.Errorf("kubeval failed")
+			},
+		}
+
+		o := newTestOptions(t, runner.Run, resourcesRule(resourcesDir, v1alpha1.Tests{
+			Kubeval: &v1alpha1.Test{},
+		}))
+		o.NoCache = false
+		o.CacheDir = cacheDir
+		o.KubevalPlugin.Binary = kubevalBinary
+		o.Settings.Spec.FailurePolicy.Advisory = []string{"kubeval"}
+		err = o.Run()
+		require.NoError(t, err, "failed to run")
+		require.Len(t, o.Outcomes, 1, "outcomes")
+		return runner, o
+	}
+
+	runner, o := runTests()
+	require.Len(t, runner.OrderedCommands, 1, "should have run kubeval")
+	expected := o.Results()
+
+	runner, o = runTests()
+	assert.Empty(t, runner.OrderedCommands, "should have used the cached results")
+	assert.True(t, o.Outcomes[0].Failed(), "cached outcome should have failed")
+	require.Len(t, o.Results(), len(expected), "cached results")
+	assert.Equal(t, expected[0].String(), o.Results()[0].String(), "cached result")
+	assert.Equal(t, serviceFile, o.Results()[0].File, "cached result file")
+
+	err = ioutil.WriteFile(serviceFile, []byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: changed\n"), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to modify %s", serviceFile)
+
+	runner, _ = runTests()
+	assert.Len(t, runner.OrderedCommands, 1, "should have run kubeval again as the resources changed")
+
+	err = ioutil.WriteFile(kubevalBinary, []byte("#!/bin/sh\necho upgraded\n"), 0755)
+	require.NoError(t, err, "failed to modify %s", kubevalBinary)
+
+	runner, _ = runTests()
+	assert.Len(t, runner.OrderedCommands, 1, "should have run kubeval again as the binary changed")
+}
+
+func TestCacheToolError(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	cacheDir := filepath.Join(tmpDir, "cache")
+	resourcesDir := filepath.Join("testdata_fixtures", "resources", "config-root")
+
+	// the tool fails without any output such as when it cannot download the schemas
+	runTests := func() *fakerunner.FakeRunner {
+		runner := &fakerunner.FakeRunner{
+			CommandRunner: func(c *cmdrunner.Command) (string, error) {
+				return "", errors.Errorf("failed to download schema")
+			},
+		}
+
+		o := newTestOptions(t, runner.Run, resourcesRule(resourcesDir, v1alpha1.Tests{
+			Kubeval: &v1alpha1.Test{},
+		}))
+		o.NoCache = false
+		o.CacheDir = cacheDir
+		err = o.Run()
+		require.Error(t, err, "should fail on the tool error")
+		require.Len(t, o.Results(), 1, "results")
+		assert.Equal(t, results.CheckToolError, o.Results()[0].CheckID, "result check")
+		return runner
+	}
+
+	runner := runTests()
+	require.Len(t, runner.OrderedCommands, 1, "should have run kubeval")
+
+	runner = runTests()
+	assert.Len(t, runner.OrderedCommands, 1, "should have run kubeval again rather than caching the tool error")
+}
@@ -0,0 +1,189 @@
+package run
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/yamls"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	// localRepositoryPrefix the prefix of chart dependency repositories which refer to local charts
+	localRepositoryPrefix = "file://"
+)
+
+// chartDependencies the dependencies of a chart in either the Chart.yaml or the requirements.yaml file
+type chartDependencies struct {
+	Dependencies []chartDependency `json:"dependencies,omitempty"`
+}
+
+// chartDependency a dependency of a chart
+type chartDependency struct {
+	Name       string `json:"name,omitempty"`
+	Repository string `json:"repository,omitempty"`
+}
+
+// findChangedFiles finds the absolute paths of the files which have changed since the --changed-since git ref
+// including any uncommitted or untracked files
+func (o *Options) findChangedFiles() error {
+	if o.ChangedSince == "" {
+		return nil
+	}
+	root, err := o.git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the root dir of the git repository")
+	}
+	root = strings.TrimSpace(root)
+
+	changed, err := o.git("diff", "--name-only", o.ChangedSince)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the files changed since %s", o.ChangedSince)
+	}
+	untracked, err := o.git("ls-files", "--others", "--exclude-standard", "--full-name")
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the untracked files")
+	}
+
+	o.changedFiles = []string{}
+	for _, line := range strings.Split(changed+"\n"+untracked, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		o.changedFiles = append(o.changedFiles, filepath.Join(root, filepath.FromSlash(line)))
+	}
+	log.Logger().Infof("found %s files changed since %s", info(len(o.changedFiles)), info(o.ChangedSince))
+
+	settingsChanged, err := o.hasChangesIn(o.SettingsFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check if the settings changed")
+	}
+	if settingsChanged {
+		log.Logger().Infof("the settings file %s has changed so testing everything", info(o.SettingsFile))
+		o.changedFiles = nil
+	}
+	return nil
+}
+
+func (o *Options) git(args ...string) (string, error) {
+	c := &cmdrunner.Command{
+		Dir:  o.Dir,
+		Name: "git",
+		Args: args,
+	}
+	return o.CommandRunner(c)
+}
+
+// hasChangesIn returns true if the file or any file in the dir has changed. If we are not testing only the changes
+// this always returns true
+func (o *Options) hasChangesIn(path string) (bool, error) {
+	if o.changedFiles == nil {
+		return true, nil
+	}
+	dir, err := o.changedPath(path)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to resolve the path of %s", path)
+	}
+	for _, f := range o.changedFiles {
+		if f == dir || strings.HasPrefix(f, dir+string(filepath.Separator)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// changedPath returns the absolute path of the given path in the same form as the changed files. git runs in the dir
+// and reports the changes relative to the real root of the repository so the path is resolved relative to the dir with
+// any symbolic links in the dir evaluated
+func (o *Options) changedPath(path string) (string, error) {
+	dir, err := filepath.Abs(o.Dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find the absolute path of %s", o.Dir)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find the absolute path of %s", path)
+	}
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find the path of %s relative to %s", path, dir)
+	}
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to evaluate the symbolic links in %s", dir)
+	}
+	return filepath.Join(realDir, rel), nil
+}
+
+// chartHasChanges returns true if the chart, any of its local dependencies or any of the values files of the chart
+// test cases have changed
+func (o *Options) chartHasChanges(charts *v1alpha1.Charts, d string) (bool, error) {
+	if o.changedFiles == nil {
+		return true, nil
+	}
+	dirs, err := localChartDirs(d, map[string]bool{})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to find the local dependencies of chart %s", d)
+	}
+	if charts != nil {
+		for i := range charts.Cases {
+			dirs = append(dirs, charts.Cases[i].ValuesFiles...)
+		}
+	}
+	for _, dir := range dirs {
+		changed, err := o.hasChangesIn(dir)
+		if err != nil {
+			return false, err
+		}
+		if changed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// localChartDirs returns the chart dir and the dirs of any local dependencies of the chart recursively
+func localChartDirs(d string, visited map[string]bool) ([]string, error) {
+	d = filepath.Clean(d)
+	if visited[d] {
+		return nil, nil
+	}
+	visited[d] = true
+	answer := []string{d}
+
+	for _, name := range []string{"Chart.yaml", "requirements.yaml"} {
+		path := filepath.Join(d, name)
+		exists, err := files.FileExists(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to check if file exists %s", path)
+		}
+		if !exists {
+			continue
+		}
+		deps := &chartDependencies{}
+		err = yamls.LoadFile(path, deps)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load file %s", path)
+		}
+		for _, dep := range deps.Dependencies {
+			if !strings.HasPrefix(dep.Repository, localRepositoryPrefix) {
+				continue
+			}
+			depDir := strings.TrimPrefix(dep.Repository, localRepositoryPrefix)
+			if !filepath.IsAbs(depDir) {
+				depDir = filepath.Join(d, depDir)
+			}
+			dirs, err := localChartDirs(depDir, visited)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to find the local dependencies of chart %s", depDir)
+			}
+			answer = append(answer, dirs...)
+		}
+	}
+	return answer, nil
+}
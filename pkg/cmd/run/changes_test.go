@@ -0,0 +1,160 @@
+package run_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner/fakerunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangedSince(t *testing.T) {
+	// the app chart depends on the lib chart which is not vendored so the charts are created in a temp dir rather
+	// than in test_data where they could not be templated by TestCmdRun
+	root, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+	for path, text := range map[string]string{
+		"charts/app/Chart.yaml":                 "apiVersion: v2\nname: app\nversion: 0.1.0\ndependencies:\n- name: lib\n  version: 0.1.0\n  repository: file://../lib\n",
+		"charts/app/templates/configmap.yaml":   "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n",
+		"charts/lib/Chart.yaml":                 "apiVersion: v2\nname: lib\nversion: 0.1.0\n",
+		"charts/lib/templates/configmap.yaml":   "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: lib\n",
+		"charts/other/Chart.yaml":               "apiVersion: v2\nname: other\nversion: 0.1.0\n",
+		"charts/other/templates/configmap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: other\n",
+		"resources/dev/configmap.yaml":          "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: dev\n  namespace: dev\n",
+		"resources/prod/configmap.yaml":         "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: prod\n  namespace: prod\n",
+	} {
+		path = filepath.Join(root, "changes", filepath.FromSlash(path))
+		err = os.MkdirAll(filepath.Dir(path), files.DefaultDirWritePermissions)
+		require.NoError(t, err, "failed to create dir for %s", path)
+		err = ioutil.WriteFile(path, []byte(text), files.DefaultFileWritePermissions)
+		require.NoError(t, err, "failed to save file %s", path)
+	}
+
+	chartsDir := filepath.Join(root, "changes", "charts")
+	resourcesDir := filepath.Join(root, "changes", "resources")
+
+	runner := &fakerunner.FakeRunner{
+		CommandRunner: func(c *cmdrunner.Command) (string, error) {
+			if c.Name != "git" {
+				return "", nil
+			}
+			switch c.Args[0] {
+			case "rev-parse":
+				return root + "\n", nil
+			case "diff":
+				return "changes/charts/lib/templates/configmap.yaml\nchanges/resources/prod/configmap.yaml\n", nil
+			default:
+				return "changes/other.txt\n", nil
+			}
+		},
+	}
+
+	tests := v1alpha1.Tests{
+		Kubeval: &v1alpha1.Test{},
+	}
+	o := newTestOptions(t, runner.Run,
+		v1alpha1.Rule{
+			Charts: &v1alpha1.Charts{
+				Dir:     chartsDir,
+				Recurse: true,
+			},
+		},
+		resourcesRule(filepath.Join(resourcesDir, "dev"), tests),
+		resourcesRule(filepath.Join(resourcesDir, "prod"), tests),
+	)
+	o.Dir = root
+	o.ChangedSince = "origin/main"
+	err = o.Run()
+	require.NoError(t, err, "failed to run")
+
+	appDir := filepath.Join(chartsDir, "app")
+	libDir := filepath.Join(chartsDir, "lib")
+	prodDir := filepath.Join(resourcesDir, "prod")
+	runner.ExpectResults(t,
+		fakerunner.FakeResult{
+			CLI: "git rev-parse --show-toplevel",
+		},
+		fakerunner.FakeResult{
+			CLI: "git diff --name-only origin/main",
+		},
+		fakerunner.FakeResult{
+			CLI: "git ls-files --others --exclude-standard --full-name",
+		},
+		fakerunner.FakeResult{
+			CLI: "helm template --output-dir " + filepath.Join(o.WorkDir, "changes", "charts", "app", "default-values") + " default-values " + appDir,
+		},
+		fakerunner.FakeResult{
+			CLI: "helm template --output-dir " + filepath.Join(o.WorkDir, "changes", "charts", "lib", "default-values") + " default-values " + libDir,
+		},
+		fakerunner.FakeResult{
+			CLI: "kubeval -d " + prodDir + " --output json",
+		},
+	)
+}
+
+func TestChangedSinceInSubdirectory(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	// git reports the changes relative to the real root of the repository even if the dir is a symbolic link to a
+	// subdirectory of the repository
+	root := filepath.Join(tmpDir, "repo")
+	for _, env := range []string{"dev", "prod"} {
+		path := filepath.Join(root, "changes", "resources", env, "configmap.yaml")
+		err = os.MkdirAll(filepath.Dir(path), files.DefaultDirWritePermissions)
+		require.NoError(t, err, "failed to create dir for %s", path)
+		err = ioutil.WriteFile(path, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: "+env+"\n  namespace: "+env+"\n"), files.DefaultFileWritePermissions)
+		require.NoError(t, err, "failed to save file %s", path)
+	}
+	dir := filepath.Join(tmpDir, "link")
+	err = os.Symlink(filepath.Join(root, "changes"), dir)
+	require.NoError(t, err, "failed to create symbolic link %s", dir)
+
+	runner := &fakerunner.FakeRunner{
+		CommandRunner: func(c *cmdrunner.Command) (string, error) {
+			if c.Name != "git" {
+				return "", nil
+			}
+			switch c.Args[0] {
+			case "rev-parse":
+				return root + "\n", nil
+			case "diff":
+				return "changes/resources/prod/configmap.yaml\n", nil
+			default:
+				return "", nil
+			}
+		},
+	}
+
+	tests := v1alpha1.Tests{
+		Kubeval: &v1alpha1.Test{},
+	}
+	o := newTestOptions(t, runner.Run,
+		resourcesRule(filepath.Join(dir, "resources", "dev"), tests),
+		resourcesRule(filepath.Join(dir, "resources", "prod"), tests),
+	)
+	o.Dir = dir
+	o.ChangedSince = "origin/main"
+	err = o.Run()
+	require.NoError(t, err, "failed to run")
+
+	runner.ExpectResults(t,
+		fakerunner.FakeResult{
+			CLI: "git rev-parse --show-toplevel",
+		},
+		fakerunner.FakeResult{
+			CLI: "git diff --name-only origin/main",
+		},
+		fakerunner.FakeResult{
+			CLI: "git ls-files --others --exclude-standard --full-name",
+		},
+		fakerunner.FakeResult{
+			CLI: "kubeval -d " + filepath.Join(dir, "resources", "prod") + " --output json",
+		},
+	)
+}
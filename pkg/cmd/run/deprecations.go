@@ -0,0 +1,26 @@
+package run
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/deprecations"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+)
+
+// deprecations checks the resources for deprecated or removed APIs in the target kubernetes version
+func (o *Options) deprecations(co *results.ResourceLocation, t *v1alpha1.DeprecationsTest) error {
+	version := co.KubernetesVersion
+	if version == "" {
+		version = t.KubernetesVersion
+	}
+	// without a target version deprecated APIs are only reported as warnings
+	failures := "resources using deprecated APIs"
+	if version != "" {
+		failures = fmt.Sprintf("resources using APIs removed in kubernetes %s", version)
+	}
+	return o.runBuiltinCheck(deprecations.ToolName, co, []string{co.OutputDir}, failures, func(resources []*manifests.Manifest) ([]*results.Result, error) {
+		return deprecations.Check(resources, co, version)
+	})
+}
@@ -0,0 +1,44 @@
+package run_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/run"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeprecations(t *testing.T) {
+	resourcesDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+	path := filepath.Join(resourcesDir, "ingress.yaml")
+	err = ioutil.WriteFile(path, []byte("apiVersion: extensions/v1beta1\nkind: Ingress\nmetadata:\n  name: myapp\n"), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to save file %s", path)
+
+	newOptions := func(version string) *run.Options {
+		return newTestOptions(t, nil, resourcesRule(resourcesDir, v1alpha1.Tests{
+			Deprecations: &v1alpha1.DeprecationsTest{
+				KubernetesVersion: version,
+			},
+		}))
+	}
+
+	o := newOptions("1.22.0")
+	err = o.Run()
+	require.Error(t, err, "should fail on the removed API")
+	require.Len(t, o.Outcomes, 1, "outcomes")
+	assert.Contains(t, o.Outcomes[0].Error.Error(), "resources using APIs removed in kubernetes 1.22.0", "outcome error")
+
+	// without a version the deprecated API is only a warning
+	o = newOptions("")
+	err = o.Run()
+	require.NoError(t, err, "should not fail on the deprecated API")
+	require.Len(t, results.Failures(o.Results()), 1, "failures")
+	assert.Equal(t, results.SeverityWarning, results.Failures(o.Results())[0].Severity, "failure severity")
+
+}
@@ -0,0 +1,53 @@
+package run
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/duplicates"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+)
+
+// checkDuplicates checks the resources in the resource dirs of all of the rules for resources which are defined more
+// than once or with conflicting namespaces. Generated resources are not checked as charts and overlays are expected
+// to generate the same resources for each of their variants
+func (o *Options) checkDuplicates() error {
+	t := o.Settings.Spec.Duplicates
+	if t == nil {
+		return nil
+	}
+	var dirs []string
+	for i := range o.Settings.Spec.Rules {
+		source := o.Settings.Spec.Rules[i].Resources
+		if source != nil && stringhelpers.StringArrayIndex(dirs, source.Dir) < 0 {
+			dirs = append(dirs, source.Dir)
+		}
+	}
+	if len(dirs) == 0 {
+		o.logger().Warnf("not checking for %s as there are no resource dirs in the rules", duplicates.ToolName)
+		return nil
+	}
+
+	co := &results.ResourceLocation{
+		Description: fmt.Sprintf("resources in %s", strings.Join(dirs, ", ")),
+		Rule:        -1,
+	}
+	if o.listing {
+		o.targets = append(o.targets, Target{
+			Rule:        co.Rule,
+			Description: co.Description,
+			Tools: []TargetTool{
+				{
+					Name: duplicates.ToolName,
+				},
+			},
+		})
+		return nil
+	}
+
+	return o.runBuiltinCheck(duplicates.ToolName, co, dirs, "duplicate or conflicting resources", func(resources []*manifests.Manifest) ([]*results.Result, error) {
+		return duplicates.Check(resources, co, t.ClusterScopedKinds), nil
+	})
+}
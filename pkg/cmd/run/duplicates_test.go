@@ -0,0 +1,49 @@
+package run_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/run"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplicates(t *testing.T) {
+	newOptions := func(failOn string) *run.Options {
+		o := newTestOptions(t, nil,
+			resourcesRule(filepath.Join("testdata_fixtures", "duplicates", "apps"), v1alpha1.Tests{}),
+			resourcesRule(filepath.Join("testdata_fixtures", "duplicates", "config"), v1alpha1.Tests{}),
+		)
+		o.Settings.Spec.Duplicates = &v1alpha1.DuplicatesTest{
+			FailOn: failOn,
+		}
+		return o
+	}
+
+	o := newOptions("")
+	err := o.Run()
+	require.Error(t, err, "should fail on the duplicate resources")
+
+	var failed []string
+	for _, r := range o.Results() {
+		if r.Failed() {
+			failed = append(failed, r.String())
+		}
+	}
+	assert.Equal(t, []string{
+		"duplicates failed duplicate on ConfigMap jx/config: the resource is also defined in " + filepath.Join("testdata_fixtures", "duplicates", "config", "config.yaml"),
+		"duplicates failed duplicate on ConfigMap jx/config: the resource is also defined in " + filepath.Join("testdata_fixtures", "duplicates", "apps", "config.yaml"),
+	}, failed, "failed results")
+	assert.Equal(t, -1, o.Results()[0].Location.Rule, "result rule")
+
+	o = newOptions("critical")
+	err = o.Run()
+	require.NoError(t, err, "should not fail below the duplicates failOn")
+
+	o = newOptions("bad")
+	err = o.Validate()
+	require.Error(t, err, "should fail to validate an invalid failOn")
+	assert.Contains(t, err.Error(), "spec.duplicates.failOn", "error message")
+}
@@ -0,0 +1,233 @@
+package run
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// defaultHelmfileEnvironment the name used for the output of the default helmfile environment
+	defaultHelmfileEnvironment = "default"
+)
+
+// helmfileState the releases declared in a helmfile
+type helmfileState struct {
+	Releases []helmfileRelease `json:"releases,omitempty"`
+}
+
+// helmfileRelease a release declared in a helmfile
+type helmfileRelease struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Chart     string `json:"chart,omitempty"`
+}
+
+// TestHelmfile templates the helmfile releases for each environment and tests the output of each release
+func (o *Options) TestHelmfile(rule *v1alpha1.Rule, helmfile *v1alpha1.Helmfile) error {
+	file := helmfile.File
+	if file == "" {
+		file = filepath.Join(o.Dir, "helmfile.yaml")
+	}
+	exists, err := files.FileExists(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check if file exists %s", file)
+	}
+	if !exists {
+		return errors.Errorf("the helmfile %s does not exist", file)
+	}
+
+	helmbin, err := o.Helm.GetBinary(nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find helm binary")
+	}
+	bin, err := o.HelmfilePlugin.GetBinary(nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find helmfile binary")
+	}
+
+	environments := helmfile.Environments
+	if len(environments) == 0 {
+		environments = []string{""}
+	}
+	for _, env := range environments {
+		env := env
+		err = o.submit(fmt.Sprintf("helmfile %s environment %s", file, env), func(o *Options) error {
+			return o.helmfileTemplateAndVerify(rule, helmfile, bin, helmbin, file, env)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to test helmfile %s environment %s", file, env)
+		}
+	}
+	return nil
+}
+
+func (o *Options) helmfileTemplateAndVerify(rule *v1alpha1.Rule, helmfile *v1alpha1.Helmfile, bin, helmbin, file, env string) error {
+	versions := o.kubernetesVersions()
+	for i := range versions {
+		err := o.helmfileTemplateAndVerifyVersion(rule, helmfile, &versions[i], bin, helmbin, file, env)
+		if err != nil {
+			return errors.Wrapf(err, "failed to template and verify kubernetes version %s", versions[i].Version)
+		}
+	}
+	return nil
+}
+
+// helmfileTemplateAndVerifyVersion templates the helmfile releases for the kubernetes version so that charts which
+// use .Capabilities generate the resources for that version and then tests the output of each release
+func (o *Options) helmfileTemplateAndVerifyVersion(rule *v1alpha1.Rule, helmfile *v1alpha1.Helmfile, kv *v1alpha1.KubernetesVersion, bin, helmbin, file, env string) error {
+	envName := env
+	if envName == "" {
+		envName = defaultHelmfileEnvironment
+	}
+	rel := filepath.Join(o.relativePath(file), envName)
+	outDir := filepath.Join(o.WorkDir, rel)
+	if kv.Version != "" {
+		outDir = filepath.Join(outDir, "kubernetes-"+kv.Version)
+	}
+	if o.listing {
+		// the releases are only known once the helmfile is templated so lets list the environment
+		co := &results.ResourceLocation{
+			Description: fmt.Sprintf("helmfile %s environment %s", file, envName),
+			Rule:        o.ruleIndex(rule),
+			SourceDir:   filepath.Dir(file),
+			OutputDir:   outDir,
+			Path:        rel,
+		}
+		return o.verifyResources(versionLocation(co, kv.Version), &rule.Tests)
+	}
+	err := os.MkdirAll(outDir, files.DefaultDirWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create output dir %s", outDir)
+	}
+
+	args := []string{"--file", file, "--helm-binary", helmbin}
+	if env != "" {
+		args = append(args, "--environment", env)
+	}
+	for _, selector := range helmfile.Selectors {
+		args = append(args, "--selector", selector)
+	}
+	args = append(args, "template")
+	var helmArgs []string
+	if kv.Version != "" {
+		helmArgs = append(helmArgs, "--kube-version", kv.Version)
+	}
+	for _, apiVersion := range kv.APIVersions {
+		helmArgs = append(helmArgs, "--api-versions", apiVersion)
+	}
+	if len(helmArgs) > 0 {
+		args = append(args, "--args", strings.Join(helmArgs, " "))
+	}
+	args = append(args, "--output-dir", outDir, "--output-dir-template", "{{ .OutputDir }}/{{ .Release.Name }}")
+	args = append(args, o.HelmfilePlugin.Args...)
+	c := &cmdrunner.Command{
+		Name: bin,
+		Args: args,
+	}
+	text, err := o.CommandRunner(c)
+	if err != nil {
+		return errors.Wrapf(err, "failed to run %s", c.CLI())
+	}
+	o.logger().Debugf(text)
+
+	releases, err := loadHelmfileReleases(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load the releases of helmfile %s", file)
+	}
+
+	// each release is generated into its own directory
+	fs, err := ioutil.ReadDir(outDir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read dir %s", outDir)
+	}
+	for _, f := range fs {
+		if !f.IsDir() {
+			continue
+		}
+		releaseName := f.Name()
+		co := &results.ResourceLocation{
+			Description: fmt.Sprintf("helmfile %s environment %s release %s", file, envName, releaseName),
+			Rule:        o.ruleIndex(rule),
+			Release:     releaseName,
+			SourceDir:   filepath.Dir(file),
+			OutputDir:   filepath.Join(outDir, releaseName),
+			Path:        filepath.Join(rel, releaseName),
+		}
+		release := releases[releaseName]
+		if release != nil {
+			co.Namespace = release.Namespace
+			co.Chart = release.Chart
+		}
+		err = o.verifyResources(versionLocation(co, kv.Version), &rule.Tests)
+		if err != nil {
+			return errors.Wrapf(err, "failed to verify helmfile output for release %s", releaseName)
+		}
+	}
+	return nil
+}
+
+// loadHelmfileReleases loads the releases declared in the helmfile indexed by name along with their namespace and the
+// dir of their chart if it is a local chart. Helmfiles are often templates so any documents after one which cannot be
+// parsed and any templated values are ignored
+func loadHelmfileReleases(file string) (map[string]*helmfileRelease, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read file %s", file)
+	}
+	answer := map[string]*helmfileRelease{}
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		state := &helmfileState{}
+		err = decoder.Decode(state)
+		if err != nil {
+			// either there are no more documents or the rest of the helmfile is a template which is not valid YAML
+			break
+		}
+		for i := range state.Releases {
+			release := &state.Releases[i]
+			if release.Name == "" || strings.Contains(release.Name, "{{") {
+				continue
+			}
+			if strings.Contains(release.Namespace, "{{") {
+				release.Namespace = ""
+			}
+			release.Chart, err = localHelmfileChart(file, release.Chart)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to find the chart of release %s", release.Name)
+			}
+			answer[release.Name] = release
+		}
+	}
+	return answer, nil
+}
+
+// localHelmfileChart returns the dir of the chart of a release if it is a local chart relative to the helmfile or an
+// empty string if the chart is in a chart repository
+func localHelmfileChart(file, chart string) (string, error) {
+	if chart == "" || strings.Contains(chart, "{{") {
+		return "", nil
+	}
+	dir := chart
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(file), dir)
+	}
+	exists, err := files.FileExists(filepath.Join(dir, "Chart.yaml"))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to check if chart exists %s", dir)
+	}
+	if !exists {
+		return "", nil
+	}
+	return dir, nil
+}
@@ -0,0 +1,164 @@
+package run_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner/fakerunner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelmfile(t *testing.T) {
+	// lets fake the generation of a dir per release
+	helmfileRunner := func(c *cmdrunner.Command) (string, error) {
+		if c.Name == "helmfile" {
+			outDir := c.Args[len(c.Args)-3]
+			for _, release := range []string{"myapp", "other"} {
+				err := os.MkdirAll(filepath.Join(outDir, release), 0755)
+				if err != nil {
+					return "", err
+				}
+			}
+		}
+		return "", nil
+	}
+	runner := &fakerunner.FakeRunner{
+		CommandRunner: helmfileRunner,
+	}
+
+	helmfile := filepath.Join("testdata_fixtures", "helmfile", "helmfile.yaml")
+	tests := v1alpha1.Tests{
+		Kubeval: &v1alpha1.Test{},
+	}
+
+	o := newTestOptions(t, runner.Run, v1alpha1.Rule{
+		Helmfile: &v1alpha1.Helmfile{
+			File:         helmfile,
+			Environments: []string{"staging", "production"},
+			Selectors:    []string{"app=myapp"},
+		},
+		Tests: tests,
+	})
+	err := o.Run()
+	require.NoError(t, err, "failed to run")
+
+	var expected []fakerunner.FakeResult
+	for _, env := range []string{"staging", "production"} {
+		outDir := filepath.Join(o.WorkDir, "testdata_fixtures", "helmfile", "helmfile.yaml", env)
+		expected = append(expected, fakerunner.FakeResult{
+			CLI: "helmfile --file " + helmfile + " --helm-binary helm --environment " + env + " --selector app=myapp template --output-dir " + outDir + " --output-dir-template {{ .OutputDir }}/{{ .Release.Name }}",
+		})
+		for _, release := range []string{"myapp", "other"} {
+			expected = append(expected, fakerunner.FakeResult{
+				CLI: "kubeval -d " + filepath.Join(outDir, release) + " --output json",
+			})
+		}
+	}
+	runner.ExpectResults(t, expected...)
+
+	require.Len(t, o.Outcomes, 4, "outcomes")
+	assert.Equal(t, "myapp", o.Outcomes[0].Location.Release, "release")
+	assert.Equal(t, "other", o.Outcomes[1].Location.Release, "release")
+
+	// lets check the releases are templated for each kubernetes version
+	runner = &fakerunner.FakeRunner{
+		CommandRunner: helmfileRunner,
+	}
+	o = newTestOptions(t, runner.Run, v1alpha1.Rule{
+		Helmfile: &v1alpha1.Helmfile{
+			File: helmfile,
+		},
+		Tests: tests,
+	})
+	o.Settings.Spec.KubernetesVersions = []v1alpha1.KubernetesVersion{
+		{
+			Version: "1.19.0",
+		},
+		{
+			Version:     "1.22.0",
+			APIVersions: []string{"networking.k8s.io/v1/Ingress"},
+		},
+	}
+	err = o.Run()
+	require.NoError(t, err, "failed to run")
+
+	expected = nil
+	for _, version := range []string{"1.19.0", "1.22.0"} {
+		outDir := filepath.Join(o.WorkDir, "testdata_fixtures", "helmfile", "helmfile.yaml", "default", "kubernetes-"+version)
+		helmArgs := "--kube-version " + version
+		if version == "1.22.0" {
+			helmArgs += " --api-versions networking.k8s.io/v1/Ingress"
+		}
+		expected = append(expected, fakerunner.FakeResult{
+			CLI: "helmfile --file " + helmfile + " --helm-binary helm template --args " + helmArgs + " --output-dir " + outDir + " --output-dir-template {{ .OutputDir }}/{{ .Release.Name }}",
+		})
+		for _, release := range []string{"myapp", "other"} {
+			expected = append(expected, fakerunner.FakeResult{
+				CLI: "kubeval -d " + filepath.Join(outDir, release) + " --kubernetes-version " + version + " --output json",
+			})
+		}
+	}
+	runner.ExpectResults(t, expected...)
+
+	require.Len(t, o.Outcomes, 4, "outcomes")
+	assert.Equal(t, "1.19.0", o.Outcomes[0].Location.KubernetesVersion, "version")
+	assert.Equal(t, filepath.Join("testdata_fixtures", "helmfile", "helmfile.yaml", "default", "myapp", "kubernetes-1.19.0"), o.Outcomes[0].Location.Path, "path")
+}
+
+func TestHelmfileReleaseNamespace(t *testing.T) {
+	// the deployment of the release does not specify a namespace so is installed in the jx namespace of the release
+	// in the helmfile rather than the namespace of the secret it uses
+	runner := &fakerunner.FakeRunner{
+		CommandRunner: func(c *cmdrunner.Command) (string, error) {
+			if c.Name != "helmfile" {
+				return "", nil
+			}
+			outDir := c.Args[len(c.Args)-3]
+			for path, text := range map[string]string{
+				"myapp/myapp/templates/deployment.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: myapp\nspec:\n  template:\n    spec:\n      containers:\n      - name: myapp\n        envFrom:\n        - secretRef:\n            name: db\n",
+				"other/myapp/templates/secret.yaml":     "apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\n  namespace: other\n",
+			} {
+				path = filepath.Join(outDir, filepath.FromSlash(path))
+				err := os.MkdirAll(filepath.Dir(path), 0755)
+				if err != nil {
+					return "", err
+				}
+				err = ioutil.WriteFile(path, []byte(text), 0600)
+				if err != nil {
+					return "", err
+				}
+			}
+			return "", nil
+		},
+	}
+
+	o := newTestOptions(t, runner.Run, v1alpha1.Rule{
+		Helmfile: &v1alpha1.Helmfile{
+			File: filepath.Join("testdata_fixtures", "helmfile", "helmfile.yaml"),
+		},
+		Tests: v1alpha1.Tests{
+			References: &v1alpha1.ReferencesTest{},
+		},
+	})
+	err := o.Run()
+	require.Error(t, err, "should fail as the secret is in a different namespace")
+
+	require.Len(t, o.Outcomes, 2, "outcomes")
+	co := o.Outcomes[0].Location
+	assert.Equal(t, "myapp", co.Release, "release")
+	assert.Equal(t, "jx", co.Namespace, "namespace")
+	assert.Equal(t, filepath.Join("test_data", "chart", "charts", "myapp"), co.Chart, "chart")
+
+	var failed []string
+	for _, r := range o.Results() {
+		if r.Failed() {
+			failed = append(failed, r.CheckID+" "+r.Kind+" "+r.Name)
+		}
+	}
+	assert.Equal(t, []string{"pod-reference Deployment myapp"}, failed, "failed results")
+}
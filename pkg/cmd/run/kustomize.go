@@ -0,0 +1,92 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/pkg/errors"
+)
+
+// TestKustomize builds the kustomize overlays and tests the output
+func (o *Options) TestKustomize(rule *v1alpha1.Rule, kustomize *v1alpha1.Kustomize) error {
+	dir := kustomize.Dir
+	exists, err := files.DirExists(dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check if dir exists %s", dir)
+	}
+	if !exists {
+		return errors.Errorf("the kustomize dir %s does not exist", dir)
+	}
+
+	overlays := kustomize.Overlays
+	if len(overlays) == 0 {
+		overlays = []string{""}
+	}
+
+	bin, err := o.KustomizePlugin.GetBinary(nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find kustomize binary")
+	}
+
+	for _, overlay := range overlays {
+		d := filepath.Join(dir, overlay)
+		err = o.submit("kustomize "+d, func(o *Options) error {
+			return o.kustomizeBuildAndVerify(rule, bin, d)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to test kustomize overlay %s", d)
+		}
+	}
+	return nil
+}
+
+func (o *Options) kustomizeBuildAndVerify(rule *v1alpha1.Rule, bin, d string) error {
+	exists, err := files.DirExists(d)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check if dir exists %s", d)
+	}
+	if !exists {
+		return errors.Errorf("the kustomize overlay dir %s does not exist", d)
+	}
+
+	rel := o.relativePath(d)
+	outDir := filepath.Join(o.WorkDir, rel)
+	co := &results.ResourceLocation{
+		Description: fmt.Sprintf("kustomize %s", d),
+		Rule:        o.ruleIndex(rule),
+		SourceDir:   d,
+		OutputDir:   outDir,
+		Path:        rel,
+	}
+	if o.listing {
+		return o.verifyResourcesForVersions(co, &rule.Tests)
+	}
+
+	err = os.MkdirAll(outDir, files.DefaultDirWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create output dir %s", outDir)
+	}
+
+	args := []string{"build", d, "--output", outDir}
+	args = append(args, o.KustomizePlugin.Args...)
+	c := &cmdrunner.Command{
+		Name: bin,
+		Args: args,
+	}
+	text, err := o.CommandRunner(c)
+	if err != nil {
+		return errors.Wrapf(err, "failed to run %s", c.CLI())
+	}
+	o.logger().Debugf(text)
+
+	err = o.verifyResourcesForVersions(co, &rule.Tests)
+	if err != nil {
+		return errors.Wrapf(err, "failed to verify kustomize output for %s", d)
+	}
+	return nil
+}
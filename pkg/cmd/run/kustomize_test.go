@@ -0,0 +1,45 @@
+package run_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner/fakerunner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKustomize(t *testing.T) {
+	runner := &fakerunner.FakeRunner{}
+
+	o := newTestOptions(t, runner.Run, v1alpha1.Rule{
+		Kustomize: &v1alpha1.Kustomize{
+			Dir:      filepath.Join("testdata_fixtures", "kustomize"),
+			Overlays: []string{"overlays/dev", "overlays/prod"},
+		},
+		Tests: v1alpha1.Tests{
+			Kubeval: &v1alpha1.Test{},
+		},
+	})
+	err := o.Run()
+	require.NoError(t, err, "failed to run")
+
+	var expected []fakerunner.FakeResult
+	for _, env := range []string{"dev", "prod"} {
+		overlay := filepath.Join("testdata_fixtures", "kustomize", "overlays", env)
+		outDir := filepath.Join(o.WorkDir, "testdata_fixtures", "kustomize", "overlays", env)
+		expected = append(expected,
+			fakerunner.FakeResult{
+				CLI: "kustomize build " + overlay + " --output " + outDir,
+			},
+			fakerunner.FakeResult{
+				CLI: "kubeval -d " + outDir + " --output json",
+			},
+		)
+	}
+	runner.ExpectResults(t, expected...)
+
+	require.Len(t, o.Outcomes, 2, "outcomes")
+	assert.Equal(t, "kustomize "+filepath.Join("testdata_fixtures", "kustomize", "overlays", "dev"), o.Outcomes[0].Location.Description, "location")
+}
@@ -0,0 +1,208 @@
+package run
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/assertions"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/deprecations"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/duplicates"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/policy"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/references"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/termcolor"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// TestOutcome the outcome of running a test tool on a resource location
+type TestOutcome struct {
+	Tool       string
+	Location   *results.ResourceLocation
+	Results    []*results.Result
+	Advisory   bool
+	Error      error
+	ReportFile string
+}
+
+// Failed returns true if the test failed
+func (t *TestOutcome) Failed() bool {
+	return t.Error != nil
+}
+
+// String returns a description of the outcome
+func (t *TestOutcome) String() string {
+	if t.Failed() {
+		return fmt.Sprintf("%s failed on %s", t.Tool, t.Location.Description)
+	}
+	return fmt.Sprintf("%s passed on %s", t.Tool, t.Location.Description)
+}
+
+// IsAdvisory returns true if failures of the given tool should be reported but not fail the run
+func IsAdvisory(policy *v1alpha1.FailurePolicy, tool string) bool {
+	name := results.NormalizeTool(tool)
+	for _, a := range policy.Advisory {
+		if results.NormalizeTool(a) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// addOutcome records the outcome of a test returning an error if the run should stop due to the failure policy
+func (o *Options) addOutcome(tool string, co *results.ResourceLocation, items []*results.Result, err error, reportFile string) error {
+	policy := &o.Settings.Spec.FailurePolicy
+	outcome := &TestOutcome{
+		Tool:       tool,
+		Location:   co,
+		Results:    items,
+		Advisory:   IsAdvisory(policy, tool),
+		Error:      err,
+		ReportFile: reportFile,
+	}
+	o.applyWaivers(outcome)
+	if outcome.Error != nil && accepted(items) {
+		outcome.Error = nil
+	}
+	outcome.Error = applyThreshold(o.threshold(tool, co), items, outcome.Error)
+	o.Outcomes = append(o.Outcomes, outcome)
+
+	if outcome.Failed() && !outcome.Advisory && policy.FailFast {
+		return errors.Wrapf(outcome.Error, "%s", outcome.String())
+	}
+	return nil
+}
+
+// accepted returns true if some failures were suppressed or waived and none of the remaining failures have at least
+// the error severity. The error of the tool then only reflects findings which were excused or which would not fail
+// the test on their own such as warnings
+func accepted(items []*results.Result) bool {
+	if len(results.FailuresAtLeast(items, results.SeverityError)) > 0 {
+		return false
+	}
+	for _, r := range items {
+		if r.Status == results.StatusSuppressed || r.Status == results.StatusWaived {
+			return true
+		}
+	}
+	return false
+}
+
+// threshold returns the minimum severity of failed checks which fail the given tool on the location. The threshold of
+// the test in the rule takes precedence over the --fail-on flag and the spec.failOn. Returns blank if not specified
+func (o *Options) threshold(tool string, co *results.ResourceLocation) results.Severity {
+	answer := ""
+	if co != nil && co.Rule >= 0 && co.Rule < len(o.Settings.Spec.Rules) {
+		answer = testFailOns(&o.Settings.Spec.Rules[co.Rule].Tests)[results.NormalizeTool(tool)]
+	} else if results.NormalizeTool(tool) == results.NormalizeTool(duplicates.ToolName) && o.Settings.Spec.Duplicates != nil {
+		answer = o.Settings.Spec.Duplicates.FailOn
+	}
+	if answer == "" {
+		answer = o.FailOn
+	}
+	if answer == "" {
+		answer = o.Settings.Spec.FailOn
+	}
+	if answer == "" {
+		return ""
+	}
+	return results.ToSeverity(answer)
+}
+
+// testFailOns returns the failOn threshold of each enabled test indexed by the normalized tool name
+func testFailOns(tests *v1alpha1.Tests) map[string]string {
+	answer := map[string]string{}
+	if tests.Conftest != nil {
+		answer[results.NormalizeTool(results.ToolConftest)] = tests.Conftest.FailOn
+	}
+	if tests.Kubeconform != nil {
+		answer[results.NormalizeTool(results.ToolKubeconform)] = tests.Kubeconform.FailOn
+	}
+	if tests.Kubescore != nil {
+		answer[results.NormalizeTool(results.ToolKubeScore)] = tests.Kubescore.FailOn
+	}
+	if tests.Kubeval != nil {
+		answer[results.NormalizeTool(results.ToolKubeval)] = tests.Kubeval.FailOn
+	}
+	if tests.Polaris != nil {
+		answer[results.NormalizeTool(results.ToolPolaris)] = tests.Polaris.FailOn
+	}
+	if tests.Deprecations != nil {
+		answer[results.NormalizeTool(deprecations.ToolName)] = tests.Deprecations.FailOn
+	}
+	if tests.Rego != nil {
+		answer[results.NormalizeTool(policy.ToolName)] = tests.Rego.FailOn
+	}
+	if len(tests.Assertions) > 0 {
+		answer[results.NormalizeTool(assertions.ToolName)] = tests.AssertionsFailOn
+	}
+	if tests.References != nil {
+		answer[results.NormalizeTool(references.ToolName)] = tests.References.FailOn
+	}
+	return answer
+}
+
+// applyThreshold returns the error of the test taking into account the severity threshold. Failures below the
+// threshold are reported but do not fail the test whereas failures at or above it fail the test even if the tool
+// passed such as kube-score warnings
+func applyThreshold(threshold results.Severity, items []*results.Result, err error) error {
+	if threshold == "" {
+		return err
+	}
+	failures := results.Failures(items)
+	blocking := results.FailuresAtLeast(failures, threshold)
+	if len(blocking) > 0 {
+		if err == nil {
+			err = errors.Errorf("%d failed checks with severity %s or above", len(blocking), threshold)
+		}
+		return err
+	}
+	if len(failures) > 0 {
+		return nil
+	}
+	return err
+}
+
+// Results returns all of the results of the tests
+func (o *Options) Results() []*results.Result {
+	var answer []*results.Result
+	for _, outcome := range o.Outcomes {
+		answer = append(answer, outcome.Results...)
+	}
+	return answer
+}
+
+// reportOutcomes logs a summary of the outcomes and returns an error if any non advisory tests failed
+func (o *Options) reportOutcomes() error {
+	var failures []string
+	advisoryCount := 0
+	for _, outcome := range o.Outcomes {
+		if !outcome.Failed() {
+			continue
+		}
+		if outcome.Advisory {
+			advisoryCount++
+			log.Logger().Warnf("advisory: %s", outcome.String())
+			continue
+		}
+		log.Logger().Errorf("%s: %s", termcolor.ColorError("FAILED"), outcome.String())
+		failures = append(failures, outcome.String())
+	}
+
+	suppressed := 0
+	for _, r := range o.Results() {
+		if r.Status != results.StatusSuppressed {
+			continue
+		}
+		suppressed++
+		log.Logger().Infof("suppressed by %s annotation: %s on %s", v1alpha1.AnnotationIgnore, r.String(), r.Location.Description)
+	}
+
+	o.reportWaivers()
+	log.Logger().Infof("ran %s tests: %s failed, %s advisory failures and %s suppressed findings", info(len(o.Outcomes)), info(len(failures)), info(advisoryCount), info(suppressed))
+	if len(failures) > 0 {
+		return errors.Errorf("%d tests failed:\n%s", len(failures), strings.Join(failures, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package run_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/run"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner/fakerunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailurePolicy(t *testing.T) {
+	testCases := []struct {
+		name          string
+		policy        v1alpha1.FailurePolicy
+		expectError   bool
+		expectedCalls int
+	}{
+		{
+			name:          "default",
+			expectError:   true,
+			expectedCalls: 2,
+		},
+		{
+			name: "advisory",
+			policy: v1alpha1.FailurePolicy{
+				Advisory: []string{"kubeval"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			name: "fail-fast",
+			policy: v1alpha1.FailurePolicy{
+				FailFast: true,
+			},
+			expectError:   true,
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		runner := &fakerunner.FakeRunner{
+			CommandRunner: func(c *cmdrunner.Command) (string, error) {
+				if c.Name == "kubeval" {
+					return "invalid resource", errors.Errorf("kubeval failed")
+				}
+				return "", nil
+			},
+		}
+
+		o := newTestOptions(t, runner.Run, resourcesRule(filepath.Join("testdata_fixtures", "resources", "config-root"), v1alpha1.Tests{
+			Kubeval:   &v1alpha1.Test{},
+			Kubescore: &v1alpha1.Test{},
+		}))
+		o.Settings.Spec.FailurePolicy = tc.policy
+		err := o.Run()
+		if tc.expectError {
+			require.Error(t, err, "for test %s", tc.name)
+			assert.Contains(t, err.Error(), "kubeval failed on resources", "for test %s", tc.name)
+		} else {
+			require.NoError(t, err, "for test %s", tc.name)
+		}
+		assert.Len(t, runner.OrderedCommands, tc.expectedCalls, "for test %s", tc.name)
+	}
+}
+
+func TestSuppressAnnotation(t *testing.T) {
+	resourcesDir := filepath.Join("testdata_fixtures", "suppressed")
+	deploymentFile := filepath.Join(resourcesDir, "deployment.yaml")
+
+	runner := &fakerunner.FakeRunner{
+		CommandRunner: func(c *cmdrunner.Command) (string, error) {
+			return `[{"filename": "` + deploymentFile + `", "kind": "Deployment", "status": "invalid", "errors": ["bad replicas"]}]`, errors.Errorf("kubeval failed")
+		},
+	}
+
+	o := newTestOptions(t, runner.Run, resourcesRule(resourcesDir, v1alpha1.Tests{
+		Kubeval: &v1alpha1.Test{},
+	}))
+	err := o.Run()
+	require.NoError(t, err, "should not fail on suppressed findings")
+
+	require.Len(t, o.Outcomes, 1, "outcomes")
+	assert.False(t, o.Outcomes[0].Failed(), "outcome should not fail")
+	require.Len(t, o.Results(), 1, "results")
+	assert.Equal(t, results.StatusSuppressed, o.Results()[0].Status, "result status")
+}
+
+func TestSuppressedFailures(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	// the critical kube-score finding and the rego deny are suppressed leaving a warning from each tool
+	resourcesDir := filepath.Join(tmpDir, "resources")
+	policyDir := filepath.Join(tmpDir, "policy")
+	for path, text := range map[string]string{
+		filepath.Join(resourcesDir, "deployment.yaml"): "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: myapp\n  namespace: jx\n  annotations:\n    kubetest.jenkins-x.io/ignore: kube-score:container-resources, rego:data.main.deny\n",
+		filepath.Join(policyDir, "main.rego"):          "package main\n\ndeny[msg] {\n  input.kind == \"Deployment\"\n  msg := \"deployments are not allowed\"\n}\n\nwarn[msg] {\n  input.kind == \"Deployment\"\n  msg := \"deployments should have a pod disruption budget\"\n}\n",
+	} {
+		err = os.MkdirAll(filepath.Dir(path), files.DefaultDirWritePermissions)
+		require.NoError(t, err, "failed to create dir for %s", path)
+		err = ioutil.WriteFile(path, []byte(text), files.DefaultFileWritePermissions)
+		require.NoError(t, err, "failed to save file %s", path)
+	}
+
+	kubeScoreOutput := `[{"type_meta": {"kind": "Deployment"}, "object_meta": {"name": "myapp", "namespace": "jx"}, "checks": [{"check": {"id": "container-resources"}, "grade": 1}, {"check": {"id": "pod-probes"}, "grade": 5}]}]`
+	testCases := []struct {
+		name        string
+		tests       v1alpha1.Tests
+		failOn      string
+		suppressed  results.Severity
+		expectError bool
+	}{
+		{
+			name: "kube-score",
+			tests: v1alpha1.Tests{
+				Kubescore: &v1alpha1.Test{},
+			},
+			suppressed: results.SeverityCritical,
+		},
+		{
+			name: "kube-score with warning threshold",
+			tests: v1alpha1.Tests{
+				Kubescore: &v1alpha1.Test{},
+			},
+			suppressed:  results.SeverityCritical,
+			failOn:      "warning",
+			expectError: true,
+		},
+		{
+			name: "rego",
+			tests: v1alpha1.Tests{
+				Rego: &v1alpha1.RegoTest{
+					Policies: []string{policyDir},
+				},
+			},
+			suppressed: results.SeverityError,
+		},
+		{
+			name: "rego with warning threshold",
+			tests: v1alpha1.Tests{
+				Rego: &v1alpha1.RegoTest{
+					Policies: []string{policyDir},
+				},
+			},
+			suppressed:  results.SeverityError,
+			failOn:      "warning",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		runner := &fakerunner.FakeRunner{
+			CommandRunner: func(c *cmdrunner.Command) (string, error) {
+				return kubeScoreOutput, errors.Errorf("kube-score failed")
+			},
+		}
+
+		o := newTestOptions(t, runner.Run, resourcesRule(resourcesDir, tc.tests))
+		o.Dir = tmpDir
+		o.Settings.Spec.FailOn = tc.failOn
+		err := o.Run()
+		if tc.expectError {
+			require.Error(t, err, "should fail on the remaining warning for %s", tc.name)
+		} else {
+			require.NoError(t, err, "should not fail on the remaining warning for %s", tc.name)
+		}
+
+		var statuses []string
+		for _, r := range o.Results() {
+			statuses = append(statuses, string(r.Severity)+" "+string(r.Status))
+		}
+		assert.ElementsMatch(t, []string{string(tc.suppressed) + " suppressed", "warning failed"}, statuses, "results for %s", tc.name)
+	}
+}
+
+func TestFailOn(t *testing.T) {
+	resourcesDir := filepath.Join("testdata_fixtures", "suppressed")
+	kubeScoreOutput := func(grade int) string {
+		return `[{"type_meta": {"kind": "Deployment"}, "object_meta": {"name": "myapp", "namespace": "jx"}, "checks": [{"check": {"id": "container-resources"}, "grade": ` + strconv.Itoa(grade) + `}]}]`
+	}
+
+	testCases := []struct {
+		name        string
+		grade       int
+		toolFails   bool
+		specFailOn  string
+		flagFailOn  string
+		testFailOn  string
+		expectError bool
+	}{
+		{name: "critical finding fails by default", grade: 1, toolFails: true, expectError: true},
+		{name: "warning passes by default", grade: 5},
+		{name: "critical finding fails with critical threshold", grade: 1, toolFails: true, specFailOn: "critical", expectError: true},
+		{name: "warning passes with critical threshold", grade: 5, toolFails: true, specFailOn: "critical"},
+		{name: "warning fails with warning threshold", grade: 5, specFailOn: "warning", expectError: true},
+		{name: "flag overrides spec", grade: 5, specFailOn: "critical", flagFailOn: "warning", expectError: true},
+		{name: "test overrides flag and spec", grade: 1, toolFails: true, specFailOn: "warning", flagFailOn: "warning", testFailOn: "critical", expectError: true},
+		{name: "test threshold allows warnings", grade: 5, specFailOn: "warning", testFailOn: "critical"},
+	}
+
+	for _, tc := range testCases {
+		runner := &fakerunner.FakeRunner{
+			CommandRunner: func(c *cmdrunner.Command) (string, error) {
+				if tc.toolFails {
+					return kubeScoreOutput(tc.grade), errors.Errorf("kube-score failed")
+				}
+				return kubeScoreOutput(tc.grade), nil
+			},
+		}
+
+		o := newTestOptions(t, runner.Run, resourcesRule(resourcesDir, v1alpha1.Tests{
+			Kubescore: &v1alpha1.Test{
+				FailOn: tc.testFailOn,
+			},
+		}))
+		o.FailOn = tc.flagFailOn
+		o.Settings.Spec.FailOn = tc.specFailOn
+		err := o.Run()
+		if tc.expectError {
+			require.Error(t, err, "should fail for %s", tc.name)
+		} else {
+			require.NoError(t, err, "should not fail for %s", tc.name)
+		}
+		require.Len(t, o.Outcomes, 1, "outcomes for %s", tc.name)
+		assert.Equal(t, tc.expectError, o.Outcomes[0].Failed(), "outcome failed for %s", tc.name)
+	}
+
+	// lets check fail fast stops the run when the threshold fails a tool which passed
+	runner := &fakerunner.FakeRunner{
+		CommandRunner: func(c *cmdrunner.Command) (string, error) {
+			return kubeScoreOutput(5), nil
+		},
+	}
+	rule := resourcesRule(resourcesDir, v1alpha1.Tests{
+		Kubescore: &v1alpha1.Test{},
+	})
+	o := newTestOptions(t, runner.Run, rule, rule)
+	o.Settings.Spec.FailOn = "warning"
+	o.Settings.Spec.FailurePolicy.FailFast = true
+	err := o.Run()
+	require.Error(t, err, "should fail fast on the warning")
+	assert.Contains(t, err.Error(), "kube-score failed on resources", "error")
+	assert.Len(t, o.Outcomes, 1, "should stop after the first failed outcome")
+
+	_, o = run.NewCmdRun()
+	o.NoCache = true
+	o.FailOn = "severe"
+	o.Settings = &v1alpha1.KubeTest{}
+	err = o.Validate()
+	require.Error(t, err, "should fail for an invalid --fail-on")
+	t.Logf("got expected error: %s", err.Error())
+}
+
+func TestTestFailOns(t *testing.T) {
+	assertion := v1alpha1.Assertion{
+		Name:       "team-label",
+		Kind:       "Deployment",
+		Expression: "has(object.metadata.labels) && has(object.metadata.labels.team)",
+		Severity:   "warning",
+	}
+	testCases := []struct {
+		field string
+		tests v1alpha1.Tests
+	}{
+		{field: "tests.conftest.failOn", tests: v1alpha1.Tests{Conftest: &v1alpha1.Test{FailOn: "severe"}}},
+		{field: "tests.kubeconform.failOn", tests: v1alpha1.Tests{Kubeconform: &v1alpha1.KubeconformTest{Test: v1alpha1.Test{FailOn: "severe"}}}},
+		{field: "tests.kubescore.failOn", tests: v1alpha1.Tests{Kubescore: &v1alpha1.Test{FailOn: "severe"}}},
+		{field: "tests.kubeval.failOn", tests: v1alpha1.Tests{Kubeval: &v1alpha1.Test{FailOn: "severe"}}},
+		{field: "tests.polaris.failOn", tests: v1alpha1.Tests{Polaris: &v1alpha1.Test{FailOn: "severe"}}},
+		{field: "tests.deprecations.failOn", tests: v1alpha1.Tests{Deprecations: &v1alpha1.DeprecationsTest{FailOn: "severe"}}},
+		{field: "tests.rego.failOn", tests: v1alpha1.Tests{Rego: &v1alpha1.RegoTest{FailOn: "severe"}}},
+		{field: "tests.references.failOn", tests: v1alpha1.Tests{References: &v1alpha1.ReferencesTest{FailOn: "severe"}}},
+		{field: "tests.assertionsFailOn", tests: v1alpha1.Tests{Assertions: []v1alpha1.Assertion{assertion}, AssertionsFailOn: "severe"}},
+	}
+	for _, tc := range testCases {
+		o := newTestOptions(t, nil, resourcesRule(filepath.Join("testdata_fixtures", "suppressed"), tc.tests))
+		err := o.Validate()
+		require.Error(t, err, "should fail for an invalid %s", tc.field)
+		assert.Contains(t, err.Error(), "spec.rules[0]."+tc.field, "error for %s", tc.field)
+	}
+
+	// lets check the failOn of the assertions overrides the spec.failOn
+	newOptions := func(failOn string) *run.Options {
+		o := newTestOptions(t, nil, resourcesRule(filepath.Join("testdata_fixtures", "suppressed"), v1alpha1.Tests{
+			Assertions:       []v1alpha1.Assertion{assertion},
+			AssertionsFailOn: failOn,
+		}))
+		o.Settings.Spec.FailOn = "warning"
+		return o
+	}
+	o := newOptions("")
+	err := o.Run()
+	require.Error(t, err, "should fail on the warning with the spec.failOn")
+	require.Len(t, results.Failures(o.Results()), 1, "failed assertions")
+
+	o = newOptions("error")
+	err = o.Run()
+	require.NoError(t, err, "should pass the warning with the assertionsFailOn")
+	require.Len(t, results.Failures(o.Results()), 1, "failed assertions")
+}
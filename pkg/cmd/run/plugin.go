@@ -13,25 +13,28 @@ type BinaryPlugin struct {
 	Binary     string
 	Version    string
 	Args       []string
+
+	// downloaded whether the binary was downloaded for the version rather than specified by the user
+	downloaded bool
 }
 
 func (o *BinaryPlugin) GetBinary(t *v1alpha1.Test) (string, error) {
 	if t != nil && t.Version != "" {
 		o.Binary = ""
 		o.Version = t.Version
-		if len(t.Args) > 0 {
-			o.Args = t.Args
-		}
-
 	}
 	if o.Binary != "" {
 		return o.Binary, nil
 	}
+	downloadLock.Lock()
+	defer downloadLock.Unlock()
+
 	var err error
 	o.Binary, err = o.DownloadFn(o.Version)
 	if err != nil {
 		return "", errors.Wrapf(err, "failed to download %s plugin", o.Name)
 	}
+	o.downloaded = true
 	return o.Binary, nil
 }
 
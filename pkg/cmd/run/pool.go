@@ -0,0 +1,154 @@
+package run
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// taskPool runs tasks on a bounded number of workers. The log output and outcomes of each task are buffered and
+// flushed in the order the tasks were submitted so that the logs are grouped by location and the reports are
+// deterministic
+type taskPool struct {
+	parent  *Options
+	workers chan struct{}
+	lock    sync.Mutex
+	tasks   []*task
+	next    int
+	failed  bool
+}
+
+// task a unit of work run on the pool with its own copy of the options
+type task struct {
+	name   string
+	opts   *Options
+	output bytes.Buffer
+	err    error
+	done   bool
+	wg     sync.WaitGroup
+}
+
+// downloadLock avoids concurrent tasks downloading the same plugin at the same time
+var downloadLock sync.Mutex
+
+func newTaskPool(parent *Options, workers int) *taskPool {
+	return &taskPool{
+		parent:  parent,
+		workers: make(chan struct{}, workers),
+	}
+}
+
+// parallelism returns the maximum number of tasks to run concurrently
+func (o *Options) parallelism() int {
+	if o.Parallelism > 0 {
+		return o.Parallelism
+	}
+	if o.Settings.Spec.Parallelism > 0 {
+		return o.Settings.Spec.Parallelism
+	}
+	return 1
+}
+
+// logger returns the logger for the current task or the default logger
+func (o *Options) logger() *logrus.Entry {
+	if o.taskLogger != nil {
+		return o.taskLogger
+	}
+	return log.Logger()
+}
+
+// submit runs the function on the pool if there is one or runs it immediately otherwise
+func (o *Options) submit(name string, fn func(o *Options) error) error {
+	if o.pool == nil {
+		return fn(o)
+	}
+	o.pool.submit(name, fn)
+	return nil
+}
+
+func (p *taskPool) submit(name string, fn func(o *Options) error) {
+	p.lock.Lock()
+	if p.failed {
+		p.lock.Unlock()
+		return
+	}
+	t := &task{
+		name: name,
+	}
+	t.opts = p.parent.taskOptions(&t.output)
+	t.wg.Add(1)
+	p.tasks = append(p.tasks, t)
+	p.lock.Unlock()
+
+	p.workers <- struct{}{}
+	go func() {
+		defer func() {
+			<-p.workers
+		}()
+
+		err := fn(t.opts)
+
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		t.err = err
+		t.done = true
+		if err != nil {
+			p.failed = true
+		}
+		p.flush()
+		t.wg.Done()
+	}()
+}
+
+// flush writes the output and outcomes of the completed tasks in submission order. It must be called with the lock
+func (p *taskPool) flush() {
+	out := log.Logger().Logger.Out
+	for ; p.next < len(p.tasks); p.next++ {
+		t := p.tasks[p.next]
+		if !t.done {
+			return
+		}
+		if t.output.Len() > 0 {
+			_, err := out.Write(t.output.Bytes())
+			if err != nil {
+				log.Logger().Warnf("failed to write the log output of %s: %s", t.name, err.Error())
+			}
+		}
+		p.parent.Outcomes = append(p.parent.Outcomes, t.opts.Outcomes...)
+	}
+}
+
+// wait waits for all the tasks to complete returning the first error in submission order
+func (p *taskPool) wait() error {
+	p.lock.Lock()
+	tasks := p.tasks
+	p.lock.Unlock()
+
+	for _, t := range tasks {
+		t.wg.Wait()
+	}
+	for _, t := range tasks {
+		if t.err != nil {
+			return errors.Wrapf(t.err, "failed to test %s", t.name)
+		}
+	}
+	return nil
+}
+
+// taskOptions returns a copy of the options for running a task which buffers its log output
+func (o *Options) taskOptions(output *bytes.Buffer) *Options {
+	parent := log.Logger()
+	l := logrus.New()
+	l.Out = output
+	l.Formatter = parent.Logger.Formatter
+	l.Level = parent.Logger.Level
+
+	answer := *o
+	answer.Outcomes = nil
+	answer.pool = nil
+	answer.taskLogger = logrus.NewEntry(l).WithFields(parent.Data)
+	return &answer
+}
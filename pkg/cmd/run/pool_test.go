@@ -0,0 +1,85 @@
+package run_test
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelism(t *testing.T) {
+	chartDir := filepath.Join("test_data", "chart", "charts", "myapp")
+	kustomizeDir := filepath.Join("testdata_fixtures", "kustomize")
+	resourcesDir := filepath.Join("testdata_fixtures", "resources", "config-root")
+
+	runTests := func(parallelism int) []string {
+		var lock sync.Mutex
+		count := 0
+		runner := func(c *cmdrunner.Command) (string, error) {
+			lock.Lock()
+			count++
+			n := count
+			lock.Unlock()
+
+			// lets make the earlier commands slower so that the tasks complete out of order
+			if n < 10 {
+				time.Sleep(time.Duration(10-n) * 5 * time.Millisecond)
+			}
+			return "", nil
+		}
+
+		tests := v1alpha1.Tests{
+			Kubeval: &v1alpha1.Test{},
+		}
+		o := newTestOptions(t, runner,
+			v1alpha1.Rule{
+				Charts: &v1alpha1.Charts{
+					Dir: chartDir,
+					Cases: []v1alpha1.ChartCase{
+						{
+							Name: "production",
+							Set:  []string{"replicaCount=3"},
+						},
+					},
+				},
+				Tests: tests,
+			},
+			v1alpha1.Rule{
+				Kustomize: &v1alpha1.Kustomize{
+					Dir:      kustomizeDir,
+					Overlays: []string{"overlays/dev", "overlays/prod"},
+				},
+				Tests: tests,
+			},
+			resourcesRule(resourcesDir, tests),
+		)
+		o.Parallelism = parallelism
+		o.Settings.Spec.KubernetesVersions = []v1alpha1.KubernetesVersion{
+			{
+				Version: "1.19.0",
+			},
+			{
+				Version: "1.22.0",
+			},
+		}
+		err := o.Run()
+		require.NoError(t, err, "failed to run with parallelism %d", parallelism)
+
+		var answer []string
+		for _, outcome := range o.Outcomes {
+			answer = append(answer, outcome.String())
+		}
+		return answer
+	}
+
+	expected := runTests(1)
+	require.Len(t, expected, 10, "outcomes")
+
+	got := runTests(4)
+	assert.Equal(t, expected, got, "the outcomes should be in the same order when run in parallel")
+}
@@ -0,0 +1,15 @@
+package run
+
+import (
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/references"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+)
+
+// references checks that the resources in the output dir refer to each other consistently
+func (o *Options) references(co *results.ResourceLocation, t *v1alpha1.ReferencesTest) error {
+	return o.runBuiltinCheck(references.ToolName, co, []string{co.OutputDir}, "invalid references", func(resources []*manifests.Manifest) ([]*results.Result, error) {
+		return references.Check(resources, co, t.Allow), nil
+	})
+}
@@ -0,0 +1,50 @@
+package run
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/policy"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/pkg/errors"
+)
+
+// policyEngines the compiled policies of each rego test so they are only loaded once per run
+type policyEngines struct {
+	lock    sync.Mutex
+	engines map[*v1alpha1.RegoTest]*policy.Engine
+}
+
+// policyEngine returns the compiled policies of the test loading them if required
+func (o *Options) policyEngine(ctx context.Context, t *v1alpha1.RegoTest) (*policy.Engine, error) {
+	if o.policies == nil {
+		return policy.Load(ctx, t)
+	}
+	o.policies.lock.Lock()
+	defer o.policies.lock.Unlock()
+
+	e := o.policies.engines[t]
+	if e != nil {
+		return e, nil
+	}
+	e, err := policy.Load(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	o.policies.engines[t] = e
+	return e, nil
+}
+
+// rego evaluates the Rego policies against the resources in process
+func (o *Options) rego(co *results.ResourceLocation, t *v1alpha1.RegoTest) error {
+	ctx := context.Background()
+	e, err := o.policyEngine(ctx, t)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load the Rego policies")
+	}
+	return o.runBuiltinCheck(policy.ToolName, co, []string{co.OutputDir}, "policy violations", func(resources []*manifests.Manifest) ([]*results.Result, error) {
+		return e.Check(ctx, resources, co)
+	})
+}
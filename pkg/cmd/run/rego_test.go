@@ -0,0 +1,42 @@
+package run_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRego(t *testing.T) {
+	resourcesDir := filepath.Join("testdata_fixtures", "suppressed")
+	runner := func(c *cmdrunner.Command) (string, error) {
+		return "", errors.Errorf("should not run command %s", c.CLI())
+	}
+
+	for _, failOn := range []string{"", "critical"} {
+		o := newTestOptions(t, runner, resourcesRule(resourcesDir, v1alpha1.Tests{
+			Rego: &v1alpha1.RegoTest{
+				Policies: []string{filepath.Join("testdata_fixtures", "policy")},
+				FailOn:   failOn,
+			},
+		}))
+		err := o.Run()
+		if failOn == "" {
+			require.Error(t, err, "should fail on the policy violation")
+		} else {
+			require.NoError(t, err, "should not fail below the %s threshold", failOn)
+		}
+
+		require.Len(t, o.Results(), 1, "results")
+		r := o.Results()[0]
+		assert.Equal(t, "rego", r.Tool, "result tool")
+		assert.Equal(t, "data.main.deny", r.CheckID, "result check")
+		assert.Equal(t, results.StatusFailed, r.Status, "result status")
+		assert.Equal(t, "container myapp must have resource limits", r.Message, "result message")
+	}
+}
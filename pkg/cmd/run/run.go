@@ -4,21 +4,31 @@ import (
 	"fmt"
 	"github.com/jenkins-x-plugins/jx-gitops/pkg/plugins"
 	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/assertions"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/baseline"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cache"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
 	ktplugins "github.com/jenkins-x-plugins/jx-kube-test/pkg/plugins"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/policy"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/reports"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/schema"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/cobras/helper"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/cobras/templates"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/options"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/termcolor"
-	"github.com/jenkins-x/jx-helpers/v3/pkg/yamls"
 	"github.com/jenkins-x/jx-logging/v3/pkg/log"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 )
 
 var (
@@ -34,30 +44,61 @@ var (
 	`)
 )
 
+// DefaultKubevalArgs the default kubeval arguments used if there is no settings file. The kubernetes version is
+// replaced by each of the spec.kubernetesVersions if they are specified
+var DefaultKubevalArgs = []string{
+	"--strict",
+	"--ignore-helm-source",
+	"--log-level",
+	"warn",
+	"--kubernetes-version=1.18.1",
+	"--additional-schema-locations",
+	"https://jenkins-x.github.io/jenkins-x-schemas",
+	"--skip-kinds",
+	"CustomResourceDefinition,Pipeline",
+}
+
 // Options the options for the command
 type Options struct {
 	options.BaseOptions
 
-	Dir             string
-	SettingsFile    string
-	WorkDir         string
-	OutFile         string
-	ChartsDir       string
-	SourceDir       string
-	RecurseCharts   bool
-	Helm            BinaryPlugin
-	ConftestPlugin  BinaryPlugin
-	HelmPlugin      BinaryPlugin
-	KubeScorePlugin BinaryPlugin
-	KubevalPlugin   BinaryPlugin
-	PolarisPlugin   BinaryPlugin
-	CommandRunner   cmdrunner.CommandRunner
-	Settings        *v1alpha1.KubeTest
-}
-
-type ResourceLocation struct {
-	Description string
-	OutputDir   string
+	Dir               string
+	SettingsFile      string
+	WorkDir           string
+	OutFile           string
+	OutFormat         string
+	ChartsDir         string
+	SourceDir         string
+	RecurseCharts     bool
+	Parallelism       int
+	NoCache           bool
+	CacheDir          string
+	CacheMaxSize      int
+	ChangedSince      string
+	FailOn            string
+	BaselineFile      string
+	Helm              BinaryPlugin
+	ConftestPlugin    BinaryPlugin
+	HelmPlugin        BinaryPlugin
+	HelmfilePlugin    BinaryPlugin
+	KubeconformPlugin BinaryPlugin
+	KubeScorePlugin   BinaryPlugin
+	KubevalPlugin     BinaryPlugin
+	KustomizePlugin   BinaryPlugin
+	PolarisPlugin     BinaryPlugin
+	CommandRunner     cmdrunner.CommandRunner
+	Settings          *v1alpha1.KubeTest
+	Baseline          *baseline.Baseline
+	Cache             *cache.Cache
+	Outcomes          []*TestOutcome
+
+	pool         *taskPool
+	taskLogger   *logrus.Entry
+	changedFiles []string
+	waivers      *waivers
+	listing      bool
+	targets      []Target
+	policies     *policyEngines
 }
 
 // NewCmdRun creates a command object for the command
@@ -74,12 +115,26 @@ func NewCmdRun() (*cobra.Command, *Options) {
 			helper.CheckErr(err)
 		},
 	}
+	o.AddTargetFlags(cmd)
+	o.AddBaselineFlags(cmd)
+	o.addRunFlags(cmd)
+	o.AddChangedSinceFlags(cmd)
+	o.addReportFlags(cmd)
+	return cmd, o
+}
+
+// AddTargetFlags adds the flags for finding the charts, overlays and resource dirs to test which are shared with the
+// commands which reuse these options
+func (o *Options) AddTargetFlags(cmd *cobra.Command) {
 	o.BaseOptions.AddBaseFlags(cmd)
 
 	o.ConftestPlugin.AddFlags(cmd, "conftest", ktplugins.ConftestVersion, ktplugins.GetConftestBinary)
 	o.Helm.AddFlags(cmd, "helm", plugins.HelmVersion, plugins.GetHelmBinary)
+	o.HelmfilePlugin.AddFlags(cmd, "helmfile", plugins.HelmfileVersion, plugins.GetHelmfileBinary)
+	o.KubeconformPlugin.AddFlags(cmd, "kubeconform", ktplugins.KubeconformVersion, ktplugins.GetKubeconformBinary)
 	o.KubeScorePlugin.AddFlags(cmd, "kubescore", ktplugins.KubeScoreVersion, ktplugins.GetKubeScoreBinary)
 	o.KubevalPlugin.AddFlags(cmd, "kubeval", ktplugins.KubevalVersion, ktplugins.GetKubevalBinary)
+	o.KustomizePlugin.AddFlags(cmd, "kustomize", ktplugins.KustomizeVersion, ktplugins.GetKustomizeBinary)
 	o.PolarisPlugin.AddFlags(cmd, "polaris", ktplugins.PolarisVersion, ktplugins.GetPolarisBinary)
 
 	cmd.Flags().StringVarP(&o.Dir, "dir", "d", ".", "the directory to look for helm, helmfile or kustomize files")
@@ -88,8 +143,31 @@ func NewCmdRun() (*cobra.Command, *Options) {
 	cmd.Flags().BoolVarP(&o.RecurseCharts, "recurse", "r", false, "should we recurse through the chart dir to find charts if no .jx/kube-test/settings.yaml file is found")
 	cmd.Flags().StringVarP(&o.SettingsFile, "settings", "s", "", "the settings file to use. If not specified will look in .jx/kube-test/settings.yaml in the directory")
 	cmd.Flags().StringVarP(&o.WorkDir, "work-dir", "w", "", "the work directory used to generate the output. If not specified a new temporary dir is created")
-	cmd.Flags().StringVarP(&o.OutFile, "output", "o", "", "the file to generate")
-	return cmd, o
+}
+
+// AddBaselineFlags adds the flags for the baseline file of waived findings
+func (o *Options) AddBaselineFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.BaselineFile, "baseline", "", "", "the baseline file of waived findings. If not specified will look in .jx/kube-test/baseline.yaml in the directory")
+}
+
+// addRunFlags adds the flags for how the tests are run and which failures fail the run
+func (o *Options) addRunFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVarP(&o.Parallelism, "parallelism", "", 0, "the maximum number of charts, values, overlays or resource dirs to test concurrently. If not specified uses the spec.parallelism in the settings or 1")
+	cmd.Flags().BoolVarP(&o.NoCache, "no-cache", "", false, "disables reusing the cached results of tools on resources which have not changed since a previous run")
+	cmd.Flags().StringVarP(&o.CacheDir, "cache-dir", "", "", "the directory used to cache the results of tools. If not specified uses the kube-test dir in the jx cache dir")
+	cmd.Flags().IntVarP(&o.CacheMaxSize, "cache-max-size", "", cache.DefaultMaxSizeMB, "the maximum size of the cache in megabytes before the least recently used results are removed")
+	cmd.Flags().StringVarP(&o.FailOn, "fail-on", "", "", "the minimum severity (info, warning, error or critical) of failed checks which fail a test. Overrides the spec.failOn in the settings but not the failOn of each test")
+}
+
+// AddChangedSinceFlags adds the flags for only testing the charts and resource dirs which have changed
+func (o *Options) AddChangedSinceFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ChangedSince, "changed-since", "", "", "if specified only test the charts and resource dirs which have changed since this git ref such as origin/main")
+}
+
+// addReportFlags adds the flags for the report of all of the results of the run
+func (o *Options) addReportFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.OutFile, "output", "o", "", "the file to generate containing a report of all of the results of the run")
+	cmd.Flags().StringVarP(&o.OutFormat, "output-format", "", "", "the format of the --output file (json, junit or sarif). If not specified uses the spec.format in the settings or the file extension")
 }
 
 // Run implements the command
@@ -120,7 +198,7 @@ func (o *Options) Validate() error {
 		}
 		if exists {
 			o.Settings = &v1alpha1.KubeTest{}
-			err = yamls.LoadFile(o.SettingsFile, o.Settings)
+			err = schema.LoadFile(o.SettingsFile, o.Settings)
 			if err != nil {
 				return errors.Wrapf(err, "failed to load file %s", o.SettingsFile)
 			}
@@ -135,6 +213,45 @@ func (o *Options) Validate() error {
 	if o.Settings == nil {
 		return errors.Errorf("failed to discover or generate settings")
 	}
+	if o.BaselineFile == "" {
+		o.BaselineFile = filepath.Join(o.Dir, ".jx", "kube-test", baseline.DefaultFileName)
+	}
+	if o.Baseline == nil {
+		exists, err := files.FileExists(o.BaselineFile)
+		if err != nil {
+			return errors.Wrapf(err, "failed to check if file exists %s", o.BaselineFile)
+		}
+		if exists {
+			o.Baseline, err = baseline.LoadFile(o.BaselineFile)
+			if err != nil {
+				return errors.Wrapf(err, "failed to load the baseline")
+			}
+			log.Logger().Debugf("loaded baseline file %s", info(o.BaselineFile))
+		}
+	}
+	if o.Cache == nil && !o.NoCache {
+		if o.CacheDir == "" {
+			o.CacheDir, err = ktplugins.CacheDir()
+			if err != nil {
+				return errors.Wrapf(err, "failed to find the cache dir")
+			}
+		}
+		o.Cache = cache.NewCache(o.CacheDir, int64(o.CacheMaxSize)*1024*1024)
+	}
+	if o.policies == nil {
+		o.policies = &policyEngines{engines: map[*v1alpha1.RegoTest]*policy.Engine{}}
+	}
+	err = o.validateFailOn()
+	if err != nil {
+		return errors.Wrapf(err, "invalid failOn severity")
+	}
+	err = o.validateAssertions()
+	if err != nil {
+		return errors.Wrapf(err, "invalid assertions")
+	}
+	if o.OutFormat != "" && reports.Writers[o.OutFormat] == nil {
+		return options.InvalidOption("output-format", o.OutFormat, reports.Formats())
+	}
 	if o.Settings.Spec.OutputDir != "" {
 		if o.Settings.Spec.Format == "" {
 			log.Logger().Warnf("no spec.format is specified in %s so defaulting to 'tap'", o.SettingsFile)
@@ -144,6 +261,53 @@ func (o *Options) Validate() error {
 	return nil
 }
 
+// validateFailOn validates the severity thresholds in the settings and command line
+func (o *Options) validateFailOn() error {
+	validate := func(name, value string) error {
+		if value == "" {
+			return nil
+		}
+		_, err := results.ParseSeverity(value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s", name)
+		}
+		return nil
+	}
+	err := validate("--fail-on", o.FailOn)
+	if err != nil {
+		return err
+	}
+	err = validate("spec.failOn", o.Settings.Spec.FailOn)
+	if err != nil {
+		return err
+	}
+	if o.Settings.Spec.Duplicates != nil {
+		err = validate("spec.duplicates.failOn", o.Settings.Spec.Duplicates.FailOn)
+		if err != nil {
+			return err
+		}
+	}
+	for i := range o.Settings.Spec.Rules {
+		failOns := testFailOns(&o.Settings.Spec.Rules[i].Tests)
+		tools := make([]string, 0, len(failOns))
+		for tool := range failOns {
+			tools = append(tools, tool)
+		}
+		sort.Strings(tools)
+		for _, tool := range tools {
+			name := fmt.Sprintf("spec.rules[%d].tests.%s.failOn", i, tool)
+			if tool == results.NormalizeTool(assertions.ToolName) {
+				name = fmt.Sprintf("spec.rules[%d].tests.assertionsFailOn", i)
+			}
+			err = validate(name, failOns[tool])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // Run implements the command
 func (o *Options) Run() error {
 	err := o.Validate()
@@ -151,28 +315,181 @@ func (o *Options) Run() error {
 		return errors.Wrapf(err, "failed to validate")
 	}
 
+	err = o.findChangedFiles()
+	if err != nil {
+		return errors.Wrapf(err, "failed to find changed files")
+	}
+	if o.Baseline != nil {
+		o.waivers = newWaivers(o.Baseline, time.Now())
+	}
+
+	err = o.TestRules()
+	reportErr := o.writeReport()
+	if err != nil {
+		return err
+	}
+	if reportErr != nil {
+		return errors.Wrapf(reportErr, "failed to write report")
+	}
+	return o.reportOutcomes()
+}
+
+// TestRules tests each of the rules in the settings. If the parallelism is greater than 1 the charts, values,
+// overlays and resource dirs are tested concurrently. Once all of the rules are tested the resource dirs are checked
+// for duplicate resources if enabled
+func (o *Options) TestRules() error {
+	workers := o.parallelism()
+	if workers <= 1 {
+		err := o.testRules()
+		if err != nil {
+			return err
+		}
+		return o.checkDuplicates()
+	}
+	o.pool = newTaskPool(o, workers)
+	err := o.testRules()
+	poolErr := o.pool.wait()
+	o.pool = nil
+	if err != nil {
+		return err
+	}
+	if poolErr != nil {
+		return poolErr
+	}
+	return o.checkDuplicates()
+}
+
+func (o *Options) testRules() error {
 	for i := range o.Settings.Spec.Rules {
 		rule := &o.Settings.Spec.Rules[i]
 
 		if rule.Charts != nil {
-			err = o.TestCharts(rule, rule.Charts)
+			err := o.TestCharts(rule, rule.Charts)
 			if err != nil {
 				return errors.Wrapf(err, "failed to test charts at %s", rule.Charts.Dir)
 			}
 			continue
 		}
+		if rule.Helmfile != nil {
+			err := o.TestHelmfile(rule, rule.Helmfile)
+			if err != nil {
+				return errors.Wrapf(err, "failed to test helmfile %s", rule.Helmfile.File)
+			}
+			continue
+		}
+		if rule.Kustomize != nil {
+			err := o.TestKustomize(rule, rule.Kustomize)
+			if err != nil {
+				return errors.Wrapf(err, "failed to test kustomize at %s", rule.Kustomize.Dir)
+			}
+			continue
+		}
 		if rule.Resources != nil {
-			err = o.TestResources(rule, rule.Resources)
+			err := o.TestResources(rule, rule.Resources)
 			if err != nil {
 				return errors.Wrapf(err, "failed to test resources at %s", rule.Resources.Dir)
 			}
 			continue
 		}
-		return errors.Errorf("invalid rule %#v has neither charts or resources", rule)
+		return errors.Errorf("invalid rule %#v has no charts, helmfile, kustomize or resources", rule)
 	}
 	return nil
 }
 
+// writeReport writes the combined report of all the results to the --output file and to the output dir if the
+// format is a report format such as junit along with the index of all the reports in the output dir
+func (o *Options) writeReport() error {
+	format := o.Settings.Spec.Format
+	outputDir := o.Settings.Spec.OutputDir
+	index := &reports.Index{}
+
+	if o.OutFile != "" {
+		outFormat := o.outputFormat()
+		err := reports.WriteFile(o.OutFile, outFormat, o.Results())
+		if err != nil {
+			return errors.Wrapf(err, "failed to write %s report", outFormat)
+		}
+		log.Logger().Infof("saved %s report in %s", outFormat, info(o.OutFile))
+
+		if outputDir != "" {
+			index.Reports = append(index.Reports, reports.IndexEntry{
+				Description: "all tests",
+				Format:      outFormat,
+				File:        o.indexPath(outputDir, o.OutFile),
+			})
+		}
+	}
+	if outputDir == "" {
+		if o.OutFile == "" && reports.IsReportFormat(format) {
+			log.Logger().Warnf("no --output or spec.outputDir is specified so cannot write the %s report", format)
+		}
+		return nil
+	}
+
+	if reports.IsReportFormat(format) {
+		path := filepath.Join(outputDir, reports.FileName(format))
+		err := reports.WriteFile(path, format, o.Results())
+		if err != nil {
+			return errors.Wrapf(err, "failed to write %s report", format)
+		}
+		log.Logger().Infof("saved %s report in %s", format, info(path))
+
+		index.Reports = append(index.Reports, reports.IndexEntry{
+			Description: "all tests",
+			Format:      format,
+			File:        reports.FileName(format),
+		})
+	}
+	for _, outcome := range o.Outcomes {
+		if outcome.ReportFile == "" {
+			continue
+		}
+		co := outcome.Location
+		index.Reports = append(index.Reports, reports.IndexEntry{
+			Tool:        outcome.Tool,
+			Description: co.Description,
+			Chart:       co.Chart,
+			Release:     co.Release,
+			SourceDir:   co.SourceDir,
+			Version:     co.KubernetesVersion,
+			Format:      format,
+			File:        o.indexPath(outputDir, outcome.ReportFile),
+			Failed:      outcome.Failed(),
+		})
+	}
+	if len(index.Reports) == 0 {
+		return nil
+	}
+	indexFile := filepath.Join(outputDir, reports.IndexFileName)
+	err := reports.SaveIndex(indexFile, index)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save index")
+	}
+	log.Logger().Infof("saved index of %s reports in %s", info(len(index.Reports)), info(indexFile))
+	return nil
+}
+
+// outputFormat returns the format of the --output file
+func (o *Options) outputFormat() string {
+	if o.OutFormat != "" {
+		return o.OutFormat
+	}
+	format := o.Settings.Spec.Format
+	if reports.Writers[format] != nil {
+		return format
+	}
+	return reports.FormatForFile(o.OutFile)
+}
+
+// indexPath returns the path of the report relative to the output dir if possible
+func (o *Options) indexPath(outputDir, path string) string {
+	rel, err := filepath.Rel(outputDir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
 // TestResources tests the resources
 func (o *Options) TestResources(rule *v1alpha1.Rule, resources *v1alpha1.Source) error {
 	dir := resources.Dir
@@ -184,11 +501,25 @@ func (o *Options) TestResources(rule *v1alpha1.Rule, resources *v1alpha1.Source)
 		return errors.Errorf("the resource dir %s does not exist", dir)
 	}
 
-	co := &ResourceLocation{
+	changed, err := o.hasChangesIn(dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check for changes in dir %s", dir)
+	}
+	if !changed {
+		log.Logger().Infof("skipping resources %s as they have not changed since %s", dir, o.ChangedSince)
+		return nil
+	}
+
+	co := &results.ResourceLocation{
 		Description: fmt.Sprintf("resources %s", dir),
+		Rule:        o.ruleIndex(rule),
+		SourceDir:   dir,
 		OutputDir:   dir,
+		Path:        o.relativePath(dir),
 	}
-	err = o.verifyResources(co, &rule.Tests)
+	err = o.submit(co.Description, func(o *Options) error {
+		return o.verifyResourcesForVersions(co, &rule.Tests)
+	})
 	if err != nil {
 		return errors.Wrapf(err, "failed to verify resources in dir  %s", dir)
 	}
@@ -221,13 +552,13 @@ func (o *Options) TestCharts(rule *v1alpha1.Rule, charts *v1alpha1.Charts) error
 			return errors.Errorf("the charts dir %s does not contain a Chart.yaml file. You can enable 'recurse: true' to find charts inside the directory", dir)
 		}
 
-		err = o.helmTemplateAndVerify(rule, helmbin, dir)
+		err = o.testChartIfChanged(rule, helmbin, dir)
 		if err != nil {
 			return errors.Wrapf(err, "failed to test chart %s", dir)
 		}
 		return nil
 	}
-	chartDirs, err := o.findChartDirs(err, dir)
+	chartDirs, err := FindChartDirs(dir)
 	if err != nil {
 		return errors.Wrapf(err, "failed to find charts in dir %s", dir)
 	}
@@ -238,7 +569,7 @@ func (o *Options) TestCharts(rule *v1alpha1.Rule, charts *v1alpha1.Charts) error
 	}
 
 	for _, d := range chartDirs {
-		err = o.helmTemplateAndVerify(rule, helmbin, d)
+		err = o.testChartIfChanged(rule, helmbin, d)
 		if err != nil {
 			return errors.Wrapf(err, "failed to test chart %s", d)
 		}
@@ -251,9 +582,49 @@ func (o *Options) TestCharts(rule *v1alpha1.Rule, charts *v1alpha1.Charts) error
 type HelmTemplateOptions struct {
 	Name        string
 	ValuesFiles []string
+	Values      string
+	Set         []string
+	Namespace   string
+	ReleaseName string
+	KubeVersion string
+	APIVersions []string
+}
+
+// testChartIfChanged tests the chart unless we are only testing changes and the chart has not changed
+func (o *Options) testChartIfChanged(rule *v1alpha1.Rule, helmbin string, d string) error {
+	changed, err := o.chartHasChanges(rule.Charts, d)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check for changes in chart %s", d)
+	}
+	if !changed {
+		log.Logger().Infof("skipping chart %s as it has not changed since %s", d, o.ChangedSince)
+		return nil
+	}
+	return o.helmTemplateAndVerify(rule, helmbin, d)
 }
 
 func (o *Options) helmTemplateAndVerify(rule *v1alpha1.Rule, helmbin string, d string) error {
+	options, err := o.FindHelmTemplateOptions(rule.Charts, d)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the values to template chart %s", d)
+	}
+
+	for i := range options {
+		opt := &options[i]
+
+		err := o.submit(fmt.Sprintf("chart %s values %s", d, opt.Name), func(o *Options) error {
+			return o.helmTemplateAndVerifyValues(rule, opt, helmbin, d)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to template and verify values %s", opt.Name)
+		}
+	}
+	return nil
+}
+
+// FindHelmTemplateOptions returns the default values, any values files found in the .jx-kube-test dir of the chart
+// and any test cases declared in the settings
+func (o *Options) FindHelmTemplateOptions(charts *v1alpha1.Charts, d string) ([]HelmTemplateOptions, error) {
 	options := []HelmTemplateOptions{
 		{
 			Name: "default-values",
@@ -264,12 +635,12 @@ func (o *Options) helmTemplateAndVerify(rule *v1alpha1.Rule, helmbin string, d s
 	kubeTestValuesDir := filepath.Join(d, ".jx-kube-test")
 	exists, err := files.DirExists(kubeTestValuesDir)
 	if err != nil {
-		return errors.Wrapf(err, "failed to check if dir exists %s", kubeTestValuesDir)
+		return nil, errors.Wrapf(err, "failed to check if dir exists %s", kubeTestValuesDir)
 	}
 	if exists {
 		fs, err := ioutil.ReadDir(kubeTestValuesDir)
 		if err != nil {
-			return errors.Wrapf(err, "failed to read dir %s", kubeTestValuesDir)
+			return nil, errors.Wrapf(err, "failed to read dir %s", kubeTestValuesDir)
 		}
 		for _, f := range fs {
 			if !f.IsDir() {
@@ -279,7 +650,7 @@ func (o *Options) helmTemplateAndVerify(rule *v1alpha1.Rule, helmbin string, d s
 			path := filepath.Join(kubeTestValuesDir, name, "values.yaml")
 			exists, err = files.FileExists(path)
 			if err != nil {
-				return errors.Wrapf(err, "failed to check if file exists %s", path)
+				return nil, errors.Wrapf(err, "failed to check if file exists %s", path)
 			}
 			if exists {
 				options = append(options, HelmTemplateOptions{
@@ -290,27 +661,86 @@ func (o *Options) helmTemplateAndVerify(rule *v1alpha1.Rule, helmbin string, d s
 		}
 	}
 
-	for i := range options {
-		opt := &options[i]
+	if charts == nil {
+		return options, nil
+	}
+	for i := range charts.Cases {
+		tc := &charts.Cases[i]
+		if tc.Name == "" {
+			return nil, errors.Errorf("chart test case %d has no name", i)
+		}
+		for j := range options {
+			if options[j].Name == tc.Name {
+				return nil, errors.Errorf("duplicate chart test case name %s", tc.Name)
+			}
+		}
+		for _, path := range tc.ValuesFiles {
+			exists, err = files.FileExists(path)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to check if file exists %s", path)
+			}
+			if !exists {
+				return nil, errors.Errorf("the values file %s of chart test case %s does not exist", path, tc.Name)
+			}
+		}
+		options = append(options, HelmTemplateOptions{
+			Name:        tc.Name,
+			ValuesFiles: tc.ValuesFiles,
+			Values:      tc.Values,
+			Set:         tc.Set,
+			Namespace:   tc.Namespace,
+			ReleaseName: tc.ReleaseName,
+			KubeVersion: tc.KubeVersion,
+			APIVersions: tc.APIVersions,
+		})
+	}
+	return options, nil
+}
 
-		err := o.helmTemplateAndVerifyValues(rule, opt, helmbin, d)
+func (o *Options) helmTemplateAndVerifyValues(rule *v1alpha1.Rule, opts *HelmTemplateOptions, helmbin, d string) error {
+	if opts.KubeVersion != "" {
+		return o.helmTemplateAndVerifyVersion(rule, opts, &v1alpha1.KubernetesVersion{Version: opts.KubeVersion}, helmbin, d)
+	}
+	versions := o.kubernetesVersions()
+	for i := range versions {
+		err := o.helmTemplateAndVerifyVersion(rule, opts, &versions[i], helmbin, d)
 		if err != nil {
-			return errors.Wrapf(err, "failed to template and verify values %s", opt.Name)
+			return errors.Wrapf(err, "failed to template and verify kubernetes version %s", versions[i].Version)
 		}
 	}
 	return nil
 }
 
-func (o *Options) helmTemplateAndVerifyValues(rule *v1alpha1.Rule, opts *HelmTemplateOptions, helmbin, d string) error {
-	rel, err := filepath.Rel(o.Dir, d)
-	if err != nil {
-		log.Logger().Warnf("failed to find relative chart dir from %s to %s", o.Dir, d)
-		rel = d
+func (o *Options) helmTemplateAndVerifyVersion(rule *v1alpha1.Rule, opts *HelmTemplateOptions, kv *v1alpha1.KubernetesVersion, helmbin, d string) error {
+	rel := o.relativePath(d)
+	path := filepath.Join(rel, opts.Name)
+	if len(o.Settings.Spec.KubernetesVersions) > 0 {
+		path = filepath.Join(path, "kubernetes-"+kv.Version)
+	}
+	outDir := filepath.Join(o.WorkDir, path)
+	releaseName := opts.ReleaseName
+	if releaseName == "" {
+		releaseName = opts.Name
 	}
-	releaseName := opts.Name
-	outDir := filepath.Join(o.WorkDir, rel, releaseName)
 
-	err = os.MkdirAll(outDir, files.DefaultDirWritePermissions)
+	co := &results.ResourceLocation{
+		Description:       fmt.Sprintf("chart %s release %s", d, releaseName),
+		Rule:              o.ruleIndex(rule),
+		Chart:             d,
+		Release:           releaseName,
+		Namespace:         opts.Namespace,
+		OutputDir:         outDir,
+		Path:              path,
+		KubernetesVersion: kv.Version,
+	}
+	if kv.Version != "" {
+		co.Description += " kubernetes " + kv.Version
+	}
+	if o.listing {
+		return o.addTarget(co, &rule.Tests)
+	}
+
+	err := os.MkdirAll(outDir, files.DefaultDirWritePermissions)
 	if err != nil {
 		return errors.Wrapf(err, "failed to create output dir %s", outDir)
 	}
@@ -319,6 +749,29 @@ func (o *Options) helmTemplateAndVerifyValues(rule *v1alpha1.Rule, opts *HelmTem
 	for _, valuesFile := range opts.ValuesFiles {
 		args = append(args, "--values", valuesFile)
 	}
+	if opts.Values != "" {
+		valuesFile := filepath.Join(o.WorkDir, rel, opts.Name+"-values.yaml")
+		err = ioutil.WriteFile(valuesFile, []byte(opts.Values), files.DefaultFileWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "failed to save file %s", valuesFile)
+		}
+		args = append(args, "--values", valuesFile)
+	}
+	for _, set := range opts.Set {
+		args = append(args, "--set", set)
+	}
+	if opts.Namespace != "" {
+		args = append(args, "--namespace", opts.Namespace)
+	}
+	if kv.Version != "" {
+		args = append(args, "--kube-version", kv.Version)
+	}
+	for _, apiVersion := range kv.APIVersions {
+		args = append(args, "--api-versions", apiVersion)
+	}
+	for _, apiVersion := range opts.APIVersions {
+		args = append(args, "--api-versions", apiVersion)
+	}
 	args = append(args, releaseName, d)
 	c := &cmdrunner.Command{
 		Name: helmbin,
@@ -329,12 +782,8 @@ func (o *Options) helmTemplateAndVerifyValues(rule *v1alpha1.Rule, opts *HelmTem
 	if err != nil {
 		return errors.Wrapf(err, "failed to run %s", c.CLI())
 	}
-	log.Logger().Debugf(text)
+	o.logger().Debugf(text)
 
-	co := &ResourceLocation{
-		Description: fmt.Sprintf("chart %s release %s", d, releaseName),
-		OutputDir:   outDir,
-	}
 	err = o.verifyResources(co, &rule.Tests)
 	if err != nil {
 		return errors.Wrapf(err, "failed to verify chart output for %s", d)
@@ -342,10 +791,10 @@ func (o *Options) helmTemplateAndVerifyValues(rule *v1alpha1.Rule, opts *HelmTem
 	return nil
 }
 
-// findChartDirs lets find all the charts in the given dir
-func (o *Options) findChartDirs(err error, dir string) ([]string, error) {
+// FindChartDirs finds the dirs containing a Chart.yaml file in the given dir
+func FindChartDirs(dir string) ([]string, error) {
 	var chartDirs []string
-	err = filepath.Walk(dir, func(path string, f os.FileInfo, err error) error {
+	err := filepath.Walk(dir, func(path string, f os.FileInfo, err error) error {
 		name := f.Name()
 		if f.IsDir() || name != "Chart.yaml" {
 			return nil
@@ -359,8 +808,11 @@ func (o *Options) findChartDirs(err error, dir string) ([]string, error) {
 	return chartDirs, err
 }
 
-func (o *Options) verifyResources(co *ResourceLocation, tests *v1alpha1.Tests) error {
-	log.Logger().Debugf("verifying %s output at %s", co.Description, co.OutputDir)
+func (o *Options) verifyResources(co *results.ResourceLocation, tests *v1alpha1.Tests) error {
+	o.logger().Debugf("verifying %s output at %s", co.Description, co.OutputDir)
+	if o.listing {
+		return o.addTarget(co, tests)
+	}
 
 	if tests.Kubeval != nil {
 		err := o.kubeval(co, tests.Kubeval)
@@ -368,6 +820,12 @@ func (o *Options) verifyResources(co *ResourceLocation, tests *v1alpha1.Tests) e
 			return errors.Wrapf(err, "failed to run kubeval on %s", co.Description)
 		}
 	}
+	if tests.Kubeconform != nil {
+		err := o.kubeconform(co, tests.Kubeconform)
+		if err != nil {
+			return errors.Wrapf(err, "failed to run kubeconform on %s", co.Description)
+		}
+	}
 	if tests.Conftest != nil {
 		err := o.conftest(co, tests.Conftest)
 		if err != nil {
@@ -386,92 +844,184 @@ func (o *Options) verifyResources(co *ResourceLocation, tests *v1alpha1.Tests) e
 			return errors.Wrapf(err, "failed to run polaris on %s", co.Description)
 		}
 	}
+	if tests.Deprecations != nil {
+		err := o.deprecations(co, tests.Deprecations)
+		if err != nil {
+			return errors.Wrapf(err, "failed to check deprecated APIs on %s", co.Description)
+		}
+	}
+	if tests.Rego != nil {
+		err := o.rego(co, tests.Rego)
+		if err != nil {
+			return errors.Wrapf(err, "failed to evaluate the Rego policies on %s", co.Description)
+		}
+	}
+	if len(tests.Assertions) > 0 {
+		err := o.checkAssertions(co, tests.Assertions)
+		if err != nil {
+			return errors.Wrapf(err, "failed to check the assertions on %s", co.Description)
+		}
+	}
+	if tests.References != nil {
+		err := o.references(co, tests.References)
+		if err != nil {
+			return errors.Wrapf(err, "failed to check the references on %s", co.Description)
+		}
+	}
 	return nil
 }
 
-func (o *Options) kubeval(co *ResourceLocation, t *v1alpha1.Test) error {
-	if len(t.Args) > 0 {
-		o.KubevalPlugin.Args = t.Args
+func (o *Options) kubeval(co *results.ResourceLocation, t *v1alpha1.Test) error {
+	c, err := o.kubevalCommand(co, t)
+	if err != nil {
+		return err
 	}
-	bin, err := o.KubevalPlugin.GetBinary(nil)
+	return o.runTestCommand(results.ToolKubeval, co, c)
+}
+
+// kubevalCommand returns the kubeval command to verify the location
+func (o *Options) kubevalCommand(co *results.ResourceLocation, t *v1alpha1.Test) (*cmdrunner.Command, error) {
+	bin, err := o.KubevalPlugin.GetBinary(t)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get the kubeval binary")
+		return nil, errors.Wrapf(err, "failed to get the kubeval binary")
 	}
 
 	args := []string{"-d", co.OutputDir}
 	args = append(args, o.KubevalPlugin.Args...)
 	args = append(args, t.Args...)
-	args = AddFormatFlags(o.Settings, "o", "output", args)
-
-	c := &cmdrunner.Command{
+	if co.KubernetesVersion != "" {
+		args = SetArgumentValue(args, "v", "kubernetes-version", schemaVersion(co.KubernetesVersion))
+	}
+	return &cmdrunner.Command{
 		Name: bin,
 		Args: args,
+	}, nil
+}
+
+func (o *Options) kubeconform(co *results.ResourceLocation, t *v1alpha1.KubeconformTest) error {
+	c, err := o.kubeconformCommand(co, t)
+	if err != nil {
+		return err
 	}
-	return o.runTestCommand("kubeval", co, c)
+	return o.runTestCommand(results.ToolKubeconform, co, c, co.OutputDir)
 }
 
-func (o *Options) kubescore(co *ResourceLocation, t *v1alpha1.Test) error {
-	bin, err := o.KubeScorePlugin.GetBinary(t)
+// kubeconformCommand returns the kubeconform command to verify the location. The output dir is passed after the
+// format flags as kubeconform stops parsing flags at the first path
+func (o *Options) kubeconformCommand(co *results.ResourceLocation, t *v1alpha1.KubeconformTest) (*cmdrunner.Command, error) {
+	bin, err := o.KubeconformPlugin.GetBinary(&t.Test)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get the kube-score binary")
+		return nil, errors.Wrapf(err, "failed to get the kubeconform binary")
+	}
+
+	// lets include the valid resources in the output so that passed checks are reported
+	args := []string{"-verbose"}
+	if t.Strict {
+		args = append(args, "-strict")
+	}
+	if t.IgnoreMissingSchemas {
+		args = append(args, "-ignore-missing-schemas")
+	}
+	if len(t.SkipKinds) > 0 {
+		args = append(args, "-skip", strings.Join(t.SkipKinds, ","))
+	}
+	if len(t.SchemaLocations) > 0 {
+		// specifying a schema location replaces the default so lets keep it
+		args = append(args, "-schema-location", "default")
+		for _, l := range t.SchemaLocations {
+			args = append(args, "-schema-location", l)
+		}
 	}
+	args = append(args, o.KubeconformPlugin.Args...)
+	args = append(args, t.Args...)
+	if co.KubernetesVersion != "" {
+		args = SetArgumentValue(args, "kubernetes-version", "kubernetes-version", schemaVersion(co.KubernetesVersion))
+	}
+	return &cmdrunner.Command{
+		Name: bin,
+		Args: args,
+	}, nil
+}
 
+func (o *Options) kubescore(co *results.ResourceLocation, t *v1alpha1.Test) error {
 	fileNames, err := o.findYAMLFiles(co.OutputDir)
 	if err != nil {
 		return errors.Wrapf(err, "failed to find YAML files in dir %s", co.OutputDir)
 	}
 	if len(fileNames) == 0 {
-		log.Logger().Warnf("no YAML files found for %s in output dir %s", co.Description, co.OutputDir)
+		o.logger().Warnf("no YAML files found for %s in output dir %s", co.Description, co.OutputDir)
 		return nil
 	}
+	c, err := o.kubescoreCommand(t, fileNames)
+	if err != nil {
+		return err
+	}
+	return o.runTestCommand(results.ToolKubeScore, co, c)
+}
+
+// kubescoreCommand returns the kube-score command to score the given files
+func (o *Options) kubescoreCommand(t *v1alpha1.Test, fileNames []string) (*cmdrunner.Command, error) {
+	bin, err := o.KubeScorePlugin.GetBinary(t)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the kube-score binary")
+	}
 
 	args := []string{"score"}
 	args = append(args, o.KubeScorePlugin.Args...)
 	args = append(args, t.Args...)
 	args = append(args, fileNames...)
-	args = AddFormatFlags(o.Settings, "o", "output", args)
-	c := &cmdrunner.Command{
+	return &cmdrunner.Command{
 		Name: bin,
 		Args: args,
-	}
+	}, nil
+}
 
-	return o.runTestCommand("kube-score", co, c)
+func (o *Options) conftest(co *results.ResourceLocation, t *v1alpha1.Test) error {
+	c, err := o.conftestCommand(co, t)
+	if err != nil {
+		return err
+	}
+	return o.runTestCommand(results.ToolConftest, co, c)
 }
 
-func (o *Options) conftest(co *ResourceLocation, t *v1alpha1.Test) error {
+// conftestCommand returns the conftest command to verify the location
+func (o *Options) conftestCommand(co *results.ResourceLocation, t *v1alpha1.Test) (*cmdrunner.Command, error) {
 	bin, err := o.ConftestPlugin.GetBinary(t)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get the polaris binary")
+		return nil, errors.Wrapf(err, "failed to get the conftest binary")
 	}
 
 	args := []string{"test", co.OutputDir}
 	args = append(args, o.ConftestPlugin.Args...)
 	args = append(args, t.Args...)
-	args = AddFormatFlags(o.Settings, "o", "output", args)
-	c := &cmdrunner.Command{
+	return &cmdrunner.Command{
 		Name: bin,
 		Args: args,
-	}
+	}, nil
+}
 
-	return o.runTestCommand("conftest", co, c)
+func (o *Options) polaris(co *results.ResourceLocation, t *v1alpha1.Test) error {
+	c, err := o.polarisCommand(co, t)
+	if err != nil {
+		return err
+	}
+	return o.runTestCommand(results.ToolPolaris, co, c)
 }
 
-func (o *Options) polaris(co *ResourceLocation, t *v1alpha1.Test) error {
+// polarisCommand returns the polaris command to audit the location
+func (o *Options) polarisCommand(co *results.ResourceLocation, t *v1alpha1.Test) (*cmdrunner.Command, error) {
 	bin, err := o.PolarisPlugin.GetBinary(t)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get the polaris binary")
+		return nil, errors.Wrapf(err, "failed to get the polaris binary")
 	}
 
 	args := []string{"audit", "--audit-path", co.OutputDir}
 	args = append(args, o.PolarisPlugin.Args...)
 	args = append(args, t.Args...)
-	args = AddFormatFlags(o.Settings, "f", "format", args)
-	c := &cmdrunner.Command{
+	return &cmdrunner.Command{
 		Name: bin,
 		Args: args,
-	}
-
-	return o.runTestCommand("polaris", co, c)
+	}, nil
 }
 
 func (o *Options) findYAMLFiles(dir string) ([]string, error) {
@@ -492,22 +1042,11 @@ func (o *Options) findYAMLFiles(dir string) ([]string, error) {
 }
 
 func (o *Options) createDefaultSettings() (*v1alpha1.KubeTest, error) {
+	// any --kubeval-args are passed to kubeval along with the arguments of the test so lets only default the test
+	// arguments if there are none
+	kubevalTest := &v1alpha1.Test{}
 	if len(o.KubevalPlugin.Args) == 0 {
-		o.KubevalPlugin.Args = []string{
-			"--strict",
-			"--ignore-helm-source",
-			"--log-level",
-			"warn",
-			"--kubernetes-version=1.18.1",
-			"--additional-schema-locations",
-			"https://jenkins-x.github.io/jenkins-x-schemas",
-			"--skip-kinds",
-			"CustomResourceDefinition,Pipeline",
-		}
-	}
-	kubevalTest := &v1alpha1.Test{
-		Version: o.KubevalPlugin.Version,
-		Args:    o.KubevalPlugin.Args,
+		kubevalTest.Args = DefaultKubevalArgs
 	}
 
 	answer := &v1alpha1.KubeTest{
@@ -524,25 +1063,16 @@ func (o *Options) createDefaultSettings() (*v1alpha1.KubeTest, error) {
 
 	if o.SourceDir == "" && o.ChartsDir == "" {
 		// lets try detect the common source dirs
-		dir := filepath.Join(o.Dir, "config-root")
-		exists, err := files.DirExists(dir)
+		var err error
+		o.SourceDir, o.ChartsDir, err = FindDefaultSources(o.Dir)
 		if err != nil {
-			return nil, errors.Wrapf(err, "failed to check if dir exists %s", dir)
+			return nil, errors.Wrapf(err, "failed to find the default source dirs")
 		}
-		if exists {
-			o.SourceDir = dir
-		} else {
-			dir = filepath.Join(o.Dir, "charts")
-			exists, err = files.DirExists(dir)
-			if err != nil {
-				return nil, errors.Wrapf(err, "failed to check if dir exists %s", dir)
-			}
-			if exists {
-				o.ChartsDir = dir
-				o.RecurseCharts = true
-			} else {
-				return nil, errors.Errorf("please specify --source-dir or --chart-dir or create a .jx/kube-test/settings.yaml file")
-			}
+		if o.SourceDir == "" && o.ChartsDir == "" {
+			return nil, errors.Errorf("please specify --source-dir or --chart-dir or create a .jx/kube-test/settings.yaml file")
+		}
+		if o.ChartsDir != "" {
+			o.RecurseCharts = true
 		}
 	}
 	if o.SourceDir != "" {
@@ -559,36 +1089,197 @@ func (o *Options) createDefaultSettings() (*v1alpha1.KubeTest, error) {
 	return answer, nil
 }
 
-func (o *Options) runTestCommand(name string, co *ResourceLocation, c *cmdrunner.Command) error {
+// FindDefaultSources finds the common source dirs in the given dir. If there is a config-root dir it is returned as
+// the source dir otherwise if there is a charts dir it is returned as the charts dir to recurse through
+func FindDefaultSources(dir string) (string, string, error) {
+	sourceDir := filepath.Join(dir, "config-root")
+	exists, err := files.DirExists(sourceDir)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to check if dir exists %s", sourceDir)
+	}
+	if exists {
+		return sourceDir, "", nil
+	}
+	chartsDir := filepath.Join(dir, "charts")
+	exists, err = files.DirExists(chartsDir)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to check if dir exists %s", chartsDir)
+	}
+	if exists {
+		return "", chartsDir, nil
+	}
+	return "", "", nil
+}
+
+// runTestCommand runs the given tool command with JSON output to parse the results. If we need to save a report in a
+// format of the tool other than JSON, such as tap, the tool has to be run a second time with that format as the report
+// cannot be rendered from the parsed results. If only the report fails the test fails with its error
+func (o *Options) runTestCommand(name string, co *results.ResourceLocation, c *cmdrunner.Command, paths ...string) error {
 	outputDir := o.Settings.Spec.OutputDir
 	format := o.Settings.Spec.Format
 
-	if outputDir != "" {
-		err := os.MkdirAll(outputDir, files.DefaultDirWritePermissions)
+	o.logger().Debugf("%s is verifying %s...", name, co.Description)
+
+	saveReport := outputDir != "" && !reports.IsReportFormat(format)
+	key := o.cacheKey(name, co, c)
+	entry := o.getCacheEntry(key, co)
+
+	var text, reportText string
+	var testErr, reportErr error
+	var duration time.Duration
+	if entry != nil {
+		o.logger().Debugf("using the cached %s results for %s", name, co.Description)
+		text = entry.Output
+		reportText = entry.Report
+		if entry.Error != "" {
+			testErr = errors.New(entry.Error)
+		}
+	} else {
+		jsonCommand, reportCommand := o.testCommands(name, c, paths...)
+		start := time.Now()
+		text, testErr = o.CommandRunner(jsonCommand)
+		duration = time.Since(start)
+		if testErr != nil {
+			o.logger().Debugf("%s returned error %s", name, testErr.Error())
+		}
+		if saveReport {
+			reportText = text
+			if reportCommand != nil {
+				o.logger().Debugf("running %s again to generate the %s report for %s", name, format, co.Description)
+				reportText, reportErr = o.CommandRunner(reportCommand)
+				if reportErr != nil {
+					o.logger().Debugf("%s returned error %s generating the %s report", name, reportErr.Error(), format)
+				}
+			}
+		}
+	}
+	items := o.parseResults(name, co, text, testErr, duration)
+	if reportErr != nil && testErr == nil {
+		// the checks passed so the report failed for another reason such as an unsupported format
+		testErr = errors.Wrapf(reportErr, "failed to generate the %s report", format)
+		items = append(items, toolErrorResult(name, co, testErr.Error(), duration))
+	} else if entry == nil && !isToolError(testErr, items) {
+		o.putCacheEntry(key, co, text, reportText, testErr)
+	}
+
+	reportFile := ""
+	if saveReport {
+		reportFile = filepath.Join(outputDir, co.Path, name+"."+format)
+		dir := filepath.Dir(reportFile)
+		err := os.MkdirAll(dir, files.DefaultDirWritePermissions)
 		if err != nil {
-			return errors.Wrapf(err, "failed to create dir %s", outputDir)
+			return errors.Wrapf(err, "failed to create dir %s", dir)
 		}
+		err = ioutil.WriteFile(reportFile, []byte(reportText), files.DefaultFileWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "failed to save file %s", reportFile)
+		}
+		o.logger().Infof("saved %s results in %s", name, info(reportFile))
+	} else if outputDir == "" {
+		o.logResults(name, co, items)
 	}
+	return o.addOutcome(name, co, items, testErr, reportFile)
+}
 
-	log.Logger().Debugf("%s is verifying %s...", name, co.Description)
+// formatFlag the short flag and option name a tool uses to choose its output format
+type formatFlag struct {
+	flag       string
+	optionName string
+}
 
-	text, err := o.CommandRunner(c)
-	if err != nil {
-		log.Logger().Debugf("%s returned error %s", name, err.Error())
+// formatFlags the format flags of each tool
+var formatFlags = map[string]formatFlag{
+	results.ToolConftest:    {flag: "o", optionName: "output"},
+	results.ToolKubeconform: {flag: "output", optionName: "output"},
+	results.ToolKubeScore:   {flag: "o", optionName: "output-format"},
+	results.ToolKubeval:     {flag: "o", optionName: "output"},
+	results.ToolPolaris:     {flag: "f", optionName: "format"},
+}
+
+// testCommands returns the command of the tool which outputs JSON to parse the results and, if a report is saved in a
+// format of the tool other than JSON, the command which generates the report. Any paths are added after the format
+// flags for tools which stop parsing flags at the first positional argument
+func (o *Options) testCommands(name string, c *cmdrunner.Command, paths ...string) (*cmdrunner.Command, *cmdrunner.Command) {
+	ff := formatFlags[name]
+	jsonCommand := *c
+	jsonCommand.Args = append(SetFormatFlags(c.Args, ff.flag, ff.optionName, "json"), paths...)
+
+	format := o.Settings.Spec.Format
+	if o.Settings.Spec.OutputDir == "" || reports.IsReportFormat(format) || format == "json" {
+		return &jsonCommand, nil
 	}
+	reportCommand := *c
+	args := append([]string{}, c.Args...)
+	reportCommand.Args = append(AddFormatFlags(o.Settings, ff.flag, ff.optionName, args), paths...)
+	return &jsonCommand, &reportCommand
+}
 
-	if outputDir != "" {
-		path := filepath.Join(outputDir, name+"."+format)
-		err = ioutil.WriteFile(path, []byte(text), files.DefaultFileWritePermissions)
+// parseResults parses the JSON output of the given tool. If the tool failed without reporting any failed results
+// we add a critical failed result for the tool itself so that the failure is not lost or hidden by a failOn threshold
+func (o *Options) parseResults(name string, co *results.ResourceLocation, text string, testErr error, duration time.Duration) []*results.Result {
+	var items []*results.Result
+	var resources []*manifests.Manifest
+	parser := results.Parsers[name]
+	if parser != nil {
+		var err error
+		items, err = parser([]byte(text), co)
 		if err != nil {
-			return errors.Wrapf(err, "failed to save file %s", path)
+			o.logger().Debugf("failed to parse %s output: %s", name, err.Error())
+			items = nil
+		} else {
+			resources, err = manifests.LoadDir(co.OutputDir)
+			if err != nil {
+				o.logger().Warnf("failed to resolve resources for %s results: %s", name, err.Error())
+			}
+			results.ResolveManifests(items, resources, co.OutputDir)
 		}
-		log.Logger().Infof("saved %s results in %s", name, info(path))
-		return nil
 	}
-	log.Logger().Infof("%s %s result:", info(name), co.Description)
-	log.Logger().Infof(text)
-	return nil
+	if testErr != nil && len(results.Failures(items)) == 0 {
+		message := strings.TrimSpace(text)
+		if message == "" {
+			message = testErr.Error()
+		}
+		items = append(items, toolErrorResult(name, co, message, duration))
+	}
+	for _, r := range items {
+		r.Duration = duration
+	}
+	results.Suppress(items, resources)
+	return items
+}
+
+// toolErrorResult returns a critical failed result for the tool itself such as when it fails without reporting any
+// failed checks
+func toolErrorResult(name string, co *results.ResourceLocation, message string, duration time.Duration) *results.Result {
+	return &results.Result{
+		Tool:     name,
+		Location: co,
+		CheckID:  results.CheckToolError,
+		Status:   results.StatusFailed,
+		Severity: results.SeverityCritical,
+		Message:  message,
+		Duration: duration,
+	}
+}
+
+// relativePath returns the path of the given dir relative to the source dir
+func (o *Options) relativePath(dir string) string {
+	rel, err := filepath.Rel(o.Dir, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		o.logger().Debugf("failed to find relative path from %s to %s", o.Dir, dir)
+		return filepath.Clean(strings.TrimPrefix(dir, string(filepath.Separator)))
+	}
+	return rel
+}
+
+// ruleIndex returns the index of the rule in the settings
+func (o *Options) ruleIndex(rule *v1alpha1.Rule) int {
+	for i := range o.Settings.Spec.Rules {
+		if &o.Settings.Spec.Rules[i] == rule {
+			return i
+		}
+	}
+	return -1
 }
 
 // AddFormatFlags if the format is specified lets add it as a command line argument
@@ -607,5 +1298,19 @@ func AddFormatFlags(settings *v1alpha1.KubeTest, flag string, optionName string,
 	return args
 }
 
-func logResult(text string) {
+// SetFormatFlags replaces any existing format argument with the given format
+func SetFormatFlags(args []string, flag string, optionName string, format string) []string {
+	return SetArgumentValue(args, flag, optionName, format)
+}
+
+func (o *Options) logResults(name string, co *results.ResourceLocation, items []*results.Result) {
+	failures := results.Failures(items)
+	if len(failures) == 0 {
+		o.logger().Infof("%s passed %s checks on %s", info(name), info(len(items)), co.Description)
+		return
+	}
+	o.logger().Infof("%s found %s failures on %s:", info(name), termcolor.ColorWarning(len(failures)), co.Description)
+	for _, r := range failures {
+		o.logger().Infof("  %s", r.String())
+	}
 }
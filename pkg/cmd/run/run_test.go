@@ -1,11 +1,19 @@
 package run_test
 
 import (
+	"encoding/json"
 	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
 	"github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/run"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/reports"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner/fakerunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/yamls"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -19,6 +27,42 @@ var (
 	generateTestOutput = true
 )
 
+// newTestOptions returns the options to test the given rules with the command runner in a new work dir. The tool
+// binaries are specified so that they are not downloaded and the cache is disabled
+func newTestOptions(t *testing.T, runner cmdrunner.CommandRunner, rules ...v1alpha1.Rule) *run.Options {
+	workDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	_, o := run.NewCmdRun()
+	o.NoCache = true
+	o.WorkDir = workDir
+	o.CommandRunner = runner
+	o.ConftestPlugin.Binary = "conftest"
+	o.Helm.Binary = "helm"
+	o.HelmfilePlugin.Binary = "helmfile"
+	o.KubeconformPlugin.Binary = "kubeconform"
+	o.KubeScorePlugin.Binary = "kube-score"
+	o.KubevalPlugin.Binary = "kubeval"
+	o.KustomizePlugin.Binary = "kustomize"
+	o.PolarisPlugin.Binary = "polaris"
+	o.Settings = &v1alpha1.KubeTest{
+		Spec: v1alpha1.KubeTestSpec{
+			Rules: rules,
+		},
+	}
+	return o
+}
+
+// resourcesRule returns a rule which runs the tests on the resources in the given dir
+func resourcesRule(dir string, tests v1alpha1.Tests) v1alpha1.Rule {
+	return v1alpha1.Rule{
+		Resources: &v1alpha1.Source{
+			Dir: dir,
+		},
+		Tests: tests,
+	}
+}
+
 func TestCmdRun(t *testing.T) {
 	tmpDir, err := ioutil.TempDir("", "")
 	require.NoError(t, err, "failed to create temp dir")
@@ -37,15 +81,19 @@ func TestCmdRun(t *testing.T) {
 		srcDir := filepath.Join(testDir, name)
 		runDir := filepath.Join(tmpDir, name)
 
-
 		t.Logf("running test %s in dir %s\n", name, runDir)
 
 		_, o := run.NewCmdRun()
+		o.NoCache = true
 		o.Dir = srcDir
 		o.ChartsDir = srcDir
 		o.RecurseCharts = true
 		o.CommandRunner = cmdrunner.DefaultCommandRunner
 		err = o.Run()
+		if strings.HasPrefix(name, "bad-") {
+			require.Error(t, err, "should have failed the tests for %s", name)
+			continue
+		}
 		require.NoError(t, err, "failed to run the command")
 
 		f := o.OutFile
@@ -114,3 +162,338 @@ func TestAddFormatFlags(t *testing.T) {
 		t.Logf("got args: %v for format %s\n", got, tc.format)
 	}
 }
+
+func TestSetFormatFlags(t *testing.T) {
+	testCases := []struct {
+		args     []string
+		expected []string
+	}{
+		{
+			expected: []string{"--output", "json"},
+		},
+		{
+			args:     []string{"-o", "tap", "--strict"},
+			expected: []string{"--strict", "--output", "json"},
+		},
+		{
+			args:     []string{"--output=tap", "-d", "foo"},
+			expected: []string{"-d", "foo", "--output", "json"},
+		},
+	}
+
+	for _, tc := range testCases {
+		got := run.SetFormatFlags(tc.args, "o", "output", "json")
+		assert.Equal(t, tc.expected, got, "for args %v", tc.args)
+	}
+}
+
+func TestReportLayout(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	runner := &fakerunner.FakeRunner{}
+	tests := v1alpha1.Tests{
+		Kubeval: &v1alpha1.Test{},
+	}
+
+	o := newTestOptions(t, runner.Run,
+		v1alpha1.Rule{
+			Charts: &v1alpha1.Charts{
+				Dir:     filepath.Join("test_data", "chart"),
+				Recurse: true,
+			},
+			Tests: tests,
+		},
+		resourcesRule(filepath.Join("testdata_fixtures", "resources", "config-root"), tests),
+	)
+	o.Settings.Spec.OutputDir = outputDir
+	o.Settings.Spec.Format = "tap"
+	err = o.Run()
+	require.NoError(t, err, "failed to run")
+
+	expectedFiles := []string{
+		filepath.Join("test_data", "chart", "charts", "myapp", "default-values", "kubeval.tap"),
+		filepath.Join("testdata_fixtures", "resources", "config-root", "kubeval.tap"),
+	}
+	for _, f := range expectedFiles {
+		assert.FileExists(t, filepath.Join(outputDir, f), "should have generated report")
+	}
+
+	index := &reports.Index{}
+	err = yamls.LoadFile(filepath.Join(outputDir, reports.IndexFileName), index)
+	require.NoError(t, err, "failed to load index")
+	require.Len(t, index.Reports, len(expectedFiles), "index entries")
+	for i, f := range expectedFiles {
+		assert.Equal(t, f, index.Reports[i].File, "index entry %d", i)
+		assert.Equal(t, "kubeval", index.Reports[i].Tool, "index entry %d", i)
+	}
+}
+
+func TestReportError(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	// the checks pass but the tool cannot generate the report in the format
+	runner := &fakerunner.FakeRunner{
+		CommandRunner: func(c *cmdrunner.Command) (string, error) {
+			if strings.Contains(strings.Join(c.Args, " "), "tap") {
+				return "", errors.Errorf("unknown format tap")
+			}
+			return `[{"filename": "testdata_fixtures/resources/config-root/namespaces/jx/service.yaml", "kind": "Service", "status": "valid", "errors": []}]`, nil
+		},
+	}
+
+	o := newTestOptions(t, runner.Run, resourcesRule(filepath.Join("testdata_fixtures", "resources", "config-root"), v1alpha1.Tests{
+		Kubeval: &v1alpha1.Test{},
+	}))
+	o.Settings.Spec.OutputDir = outputDir
+	o.Settings.Spec.Format = "tap"
+	err = o.Run()
+	require.Error(t, err, "should fail when the report cannot be generated")
+	require.Len(t, runner.OrderedCommands, 2, "should have run kubeval for the results and the report")
+
+	require.Len(t, o.Outcomes, 1, "outcomes")
+	assert.True(t, o.Outcomes[0].Failed(), "outcome should have failed")
+	failures := results.Failures(o.Results())
+	require.Len(t, failures, 1, "failures")
+	assert.Equal(t, results.CheckToolError, failures[0].CheckID, "failure check")
+	assert.Contains(t, failures[0].Message, "unknown format tap", "failure message")
+}
+
+func TestOutputFile(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	runner := &fakerunner.FakeRunner{
+		ResultOutput: `[{"filename": "testdata_fixtures/resources/config-root/namespaces/jx/service.yaml", "kind": "Service", "status": "valid", "errors": []}]`,
+	}
+
+	o := newTestOptions(t, runner.Run, resourcesRule(filepath.Join("testdata_fixtures", "resources", "config-root"), v1alpha1.Tests{
+		Kubeval: &v1alpha1.Test{},
+	}))
+	o.OutFile = filepath.Join(tmpDir, "results.json")
+	err = o.Run()
+	require.NoError(t, err, "failed to run")
+	require.FileExists(t, o.OutFile, "should have generated the output file")
+
+	data, err := ioutil.ReadFile(o.OutFile)
+	require.NoError(t, err, "failed to load %s", o.OutFile)
+
+	report := &reports.JSONReport{}
+	err = json.Unmarshal(data, report)
+	require.NoError(t, err, "failed to parse %s", o.OutFile)
+
+	assert.Equal(t, reports.Summary{Total: 1, Passed: 1}, report.Summary, "summary")
+	require.Len(t, report.Results, 1, "results")
+	assert.Equal(t, "myapp", report.Results[0].Name, "result name")
+	assert.Equal(t, "kubeval", report.Results[0].Tool, "result tool")
+}
+
+func TestChartCases(t *testing.T) {
+	runner := &fakerunner.FakeRunner{}
+
+	chartDir := filepath.Join("test_data", "chart", "charts", "myapp")
+	valuesFile := filepath.Join("testdata_fixtures", "values", "production.yaml")
+
+	o := newTestOptions(t, runner.Run, v1alpha1.Rule{
+		Charts: &v1alpha1.Charts{
+			Dir: chartDir,
+			Cases: []v1alpha1.ChartCase{
+				{
+					Name:        "production",
+					ValuesFiles: []string{valuesFile},
+					Values:      "service:\n  type: LoadBalancer\n",
+					Set:         []string{"image.tag=1.2.3"},
+					Namespace:   "jx-production",
+					ReleaseName: "myapp",
+					KubeVersion: "1.20.0",
+					APIVersions: []string{"networking.k8s.io/v1/Ingress"},
+				},
+			},
+		},
+	})
+	err := o.Run()
+	require.NoError(t, err, "failed to run")
+
+	outDir := filepath.Join(o.WorkDir, "test_data", "chart", "charts", "myapp")
+	runner.ExpectResults(t,
+		fakerunner.FakeResult{
+			CLI: "helm template --output-dir " + filepath.Join(outDir, "default-values") + " default-values " + chartDir,
+		},
+		fakerunner.FakeResult{
+			CLI: "helm template --output-dir " + filepath.Join(outDir, "production") +
+				" --values " + valuesFile +
+				" --values " + filepath.Join(outDir, "production-values.yaml") +
+				" --set image.tag=1.2.3 --namespace jx-production --kube-version 1.20.0 --api-versions networking.k8s.io/v1/Ingress myapp " + chartDir,
+		},
+	)
+	assert.FileExists(t, filepath.Join(outDir, "production-values.yaml"), "should have saved the inline values")
+}
+
+func TestChartCaseReleaseName(t *testing.T) {
+	// lets fake the templating of the chart into the output dir
+	runner := &fakerunner.FakeRunner{
+		CommandRunner: func(c *cmdrunner.Command) (string, error) {
+			path := filepath.Join(c.Args[2], "configmap.yaml")
+			err := os.MkdirAll(filepath.Dir(path), files.DefaultDirWritePermissions)
+			if err != nil {
+				return "", err
+			}
+			return "", ioutil.WriteFile(path, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cheese\n"), files.DefaultFileWritePermissions)
+		},
+	}
+
+	chartDir := filepath.Join("test_data", "chart", "charts", "myapp")
+	o := newTestOptions(t, runner.Run, v1alpha1.Rule{
+		Charts: &v1alpha1.Charts{
+			Dir: chartDir,
+			Cases: []v1alpha1.ChartCase{
+				{
+					Name:        "production",
+					ReleaseName: "myapp",
+				},
+			},
+		},
+		Tests: v1alpha1.Tests{
+			Deprecations: &v1alpha1.DeprecationsTest{},
+		},
+	})
+	err := o.Run()
+	require.NoError(t, err, "failed to run")
+
+	require.Len(t, o.Results(), 2, "results")
+	assert.Equal(t, "default-values", o.Results()[0].Location.Release, "release of the default values")
+	assert.Equal(t, "myapp", o.Results()[1].Location.Release, "release of the test case")
+	assert.Equal(t, "chart "+chartDir+" release myapp", o.Results()[1].Location.Description, "description of the test case")
+	assert.Equal(t, filepath.Join("test_data", "chart", "charts", "myapp", "production"), o.Results()[1].Location.Path, "path of the test case")
+}
+
+func TestKubernetesVersions(t *testing.T) {
+	runner := &fakerunner.FakeRunner{}
+
+	chartDir := filepath.Join("test_data", "chart", "charts", "myapp")
+	resourcesDir := filepath.Join("testdata_fixtures", "resources", "config-root")
+
+	o := newTestOptions(t, runner.Run,
+		v1alpha1.Rule{
+			Charts: &v1alpha1.Charts{
+				Dir: chartDir,
+			},
+		},
+		resourcesRule(resourcesDir, v1alpha1.Tests{
+			Kubeval: &v1alpha1.Test{},
+		}),
+	)
+	o.KubevalPlugin.Args = []string{"--strict", "--kubernetes-version=1.18.1"}
+	o.Settings.Spec.KubernetesVersions = []v1alpha1.KubernetesVersion{
+		{
+			Version: "1.19.0",
+		},
+		{
+			Version:     "1.22.0",
+			APIVersions: []string{"networking.k8s.io/v1/Ingress"},
+		},
+	}
+	err := o.Run()
+	require.NoError(t, err, "failed to run")
+
+	outDir := filepath.Join(o.WorkDir, "test_data", "chart", "charts", "myapp", "default-values")
+	runner.ExpectResults(t,
+		fakerunner.FakeResult{
+			CLI: "helm template --output-dir " + filepath.Join(outDir, "kubernetes-1.19.0") + " --kube-version 1.19.0 default-values " + chartDir,
+		},
+		fakerunner.FakeResult{
+			CLI: "helm template --output-dir " + filepath.Join(outDir, "kubernetes-1.22.0") + " --kube-version 1.22.0 --api-versions networking.k8s.io/v1/Ingress default-values " + chartDir,
+		},
+		fakerunner.FakeResult{
+			CLI: "kubeval -d " + resourcesDir + " --strict --kubernetes-version 1.19.0 --output json",
+		},
+		fakerunner.FakeResult{
+			CLI: "kubeval -d " + resourcesDir + " --strict --kubernetes-version 1.22.0 --output json",
+		},
+	)
+
+	require.Len(t, o.Outcomes, 2, "outcomes")
+	assert.Equal(t, "1.19.0", o.Outcomes[0].Location.KubernetesVersion, "version")
+	assert.Equal(t, "resources "+resourcesDir+" kubernetes 1.22.0", o.Outcomes[1].Location.Description, "description")
+}
+
+func TestKubeconform(t *testing.T) {
+	runner := &fakerunner.FakeRunner{}
+
+	resourcesDir := filepath.Join("testdata_fixtures", "resources", "config-root")
+
+	o := newTestOptions(t, runner.Run, resourcesRule(resourcesDir, v1alpha1.Tests{
+		Kubeconform: &v1alpha1.KubeconformTest{
+			SchemaLocations: []string{"https://jenkins-x.github.io/jenkins-x-schemas/{{ .ResourceKind }}.json"},
+			Strict:          true,
+			SkipKinds:       []string{"CustomResourceDefinition", "Pipeline"},
+		},
+	}))
+	o.Settings.Spec.KubernetesVersions = []v1alpha1.KubernetesVersion{
+		{
+			Version: "v1.21.0",
+		},
+	}
+	err := o.Run()
+	require.NoError(t, err, "failed to run")
+
+	runner.ExpectResults(t,
+		fakerunner.FakeResult{
+			CLI: "kubeconform -verbose -strict -skip CustomResourceDefinition,Pipeline -schema-location default -schema-location https://jenkins-x.github.io/jenkins-x-schemas/{{ .ResourceKind }}.json --kubernetes-version 1.21.0 --output json " + resourcesDir,
+		},
+	)
+
+	require.Len(t, o.Outcomes, 1, "outcomes")
+	assert.Equal(t, "kubeconform", o.Outcomes[0].Tool, "tool")
+}
+
+func TestKubevalArgs(t *testing.T) {
+	runner := &fakerunner.FakeRunner{}
+
+	resourcesDir := filepath.Join("testdata_fixtures", "resources", "config-root")
+
+	o := newTestOptions(t, runner.Run,
+		resourcesRule(resourcesDir, v1alpha1.Tests{
+			Kubeval: &v1alpha1.Test{
+				Args: []string{"--ignore-missing-schemas"},
+			},
+		}),
+		resourcesRule(resourcesDir, v1alpha1.Tests{
+			Kubeval: &v1alpha1.Test{},
+		}),
+	)
+	o.KubevalPlugin.Args = []string{"--strict"}
+	err := o.Run()
+	require.NoError(t, err, "failed to run")
+
+	runner.ExpectResults(t,
+		fakerunner.FakeResult{
+			CLI: "kubeval -d " + resourcesDir + " --strict --ignore-missing-schemas --output json",
+		},
+		fakerunner.FakeResult{
+			CLI: "kubeval -d " + resourcesDir + " --strict --output json",
+		},
+	)
+	assert.Equal(t, []string{"--strict"}, o.KubevalPlugin.Args, "should not modify the plugin arguments")
+}
+
+func TestDefaultSettings(t *testing.T) {
+	runner := &fakerunner.FakeRunner{}
+
+	resourcesDir := filepath.Join("testdata_fixtures", "resources", "config-root")
+
+	o := newTestOptions(t, runner.Run)
+	o.Settings = nil
+	o.SettingsFile = filepath.Join(o.WorkDir, "settings.yaml")
+	o.SourceDir = resourcesDir
+	err := o.Run()
+	require.NoError(t, err, "failed to run")
+
+	runner.ExpectResults(t,
+		fakerunner.FakeResult{
+			CLI: "kubeval -d " + resourcesDir + " " + strings.Join(run.DefaultKubevalArgs, " ") + " --output json",
+		},
+	)
+	assert.Contains(t, runner.OrderedCommands[0].CLI(), "--kubernetes-version=1.18.1", "should use the default kubernetes version")
+}
@@ -0,0 +1,178 @@
+package run
+
+import (
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/assertions"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/deprecations"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/policy"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/references"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/open-policy-agent/opa/version"
+	"github.com/pkg/errors"
+)
+
+// Target a chart release, kustomize overlay, helmfile environment or resource dir which would be tested along with the
+// tools which would be run on it
+type Target struct {
+	// Rule the index of the rule in the settings or -1 if the target is not for a single rule
+	Rule int `json:"rule"`
+
+	// Description the description of the target
+	Description string `json:"description"`
+
+	// Chart the chart dir if the target is a chart release
+	Chart string `json:"chart,omitempty"`
+
+	// Release the values variant of the chart or the release name
+	Release string `json:"release,omitempty"`
+
+	// SourceDir the source dir of the resources, kustomize overlay or helmfile
+	SourceDir string `json:"sourceDir,omitempty"`
+
+	// OutputDir the dir containing the resources to test
+	OutputDir string `json:"outputDir,omitempty"`
+
+	// KubernetesVersion the target kubernetes version if any
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+
+	// Tools the tools which would be run on the target
+	Tools []TargetTool `json:"tools,omitempty"`
+}
+
+// TargetTool a tool which would be run on a target with its resolved version and arguments. ReportArgs are the
+// arguments of the second run of the tool which generates the report if it is saved in a format other than JSON
+type TargetTool struct {
+	Name       string   `json:"name"`
+	Version    string   `json:"version,omitempty"`
+	Args       []string `json:"args,omitempty"`
+	ReportArgs []string `json:"reportArgs,omitempty"`
+}
+
+// FindTargets resolves the rules in the settings into the targets and tools which would be tested without downloading
+// any tools, templating any charts or running any tests. Validate must be called first
+func (o *Options) FindTargets() ([]Target, error) {
+	err := o.findChangedFiles()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find changed files")
+	}
+
+	// lets avoid downloading any binaries as we only need their versions. The plugins are restored afterwards as
+	// resolving the binary also changes the binary, version and arguments of the plugin
+	plugins := []*BinaryPlugin{&o.Helm, &o.HelmfilePlugin, &o.KustomizePlugin, &o.ConftestPlugin, &o.KubeconformPlugin, &o.KubeScorePlugin, &o.KubevalPlugin, &o.PolarisPlugin}
+	for _, p := range plugins {
+		saved := *p
+		name := p.Name
+		p.DownloadFn = func(string) (string, error) {
+			return name, nil
+		}
+		defer func(p *BinaryPlugin) {
+			*p = saved
+		}(p)
+	}
+
+	o.listing = true
+	o.targets = nil
+	defer func() {
+		o.listing = false
+	}()
+
+	err = o.testRules()
+	if err != nil {
+		return nil, err
+	}
+	err = o.checkDuplicates()
+	if err != nil {
+		return nil, err
+	}
+	return o.targets, nil
+}
+
+// addTarget records the target and the commands of each tool which would be run on the location
+func (o *Options) addTarget(co *results.ResourceLocation, tests *v1alpha1.Tests) error {
+	target := Target{
+		Rule:              co.Rule,
+		Description:       co.Description,
+		Chart:             co.Chart,
+		Release:           co.Release,
+		SourceDir:         co.SourceDir,
+		OutputDir:         co.OutputDir,
+		KubernetesVersion: co.KubernetesVersion,
+	}
+	addTool := func(name string, plugin *BinaryPlugin, c *cmdrunner.Command, err error, paths ...string) error {
+		if err != nil {
+			return errors.Wrapf(err, "failed to create the %s command for %s", name, co.Description)
+		}
+		jsonCommand, reportCommand := o.testCommands(name, c, paths...)
+		tool := TargetTool{
+			Name:    name,
+			Version: plugin.Version,
+			Args:    jsonCommand.Args,
+		}
+		if reportCommand != nil {
+			tool.ReportArgs = reportCommand.Args
+		}
+		target.Tools = append(target.Tools, tool)
+		return nil
+	}
+
+	if tests.Kubeval != nil {
+		c, err := o.kubevalCommand(co, tests.Kubeval)
+		err = addTool(results.ToolKubeval, &o.KubevalPlugin, c, err)
+		if err != nil {
+			return err
+		}
+	}
+	if tests.Kubeconform != nil {
+		c, err := o.kubeconformCommand(co, tests.Kubeconform)
+		err = addTool(results.ToolKubeconform, &o.KubeconformPlugin, c, err, co.OutputDir)
+		if err != nil {
+			return err
+		}
+	}
+	if tests.Conftest != nil {
+		c, err := o.conftestCommand(co, tests.Conftest)
+		err = addTool(results.ToolConftest, &o.ConftestPlugin, c, err)
+		if err != nil {
+			return err
+		}
+	}
+	if tests.Kubescore != nil {
+		// the YAML files are only known once the resources are generated so lets show the dir
+		c, err := o.kubescoreCommand(tests.Kubescore, []string{co.OutputDir})
+		err = addTool(results.ToolKubeScore, &o.KubeScorePlugin, c, err)
+		if err != nil {
+			return err
+		}
+	}
+	if tests.Polaris != nil {
+		c, err := o.polarisCommand(co, tests.Polaris)
+		err = addTool(results.ToolPolaris, &o.PolarisPlugin, c, err)
+		if err != nil {
+			return err
+		}
+	}
+	if tests.Deprecations != nil {
+		target.Tools = append(target.Tools, TargetTool{
+			Name: deprecations.ToolName,
+		})
+	}
+	if tests.Rego != nil {
+		target.Tools = append(target.Tools, TargetTool{
+			Name:    policy.ToolName,
+			Version: version.Version,
+		})
+	}
+	if len(tests.Assertions) > 0 {
+		target.Tools = append(target.Tools, TargetTool{
+			Name: assertions.ToolName,
+		})
+	}
+	if tests.References != nil {
+		target.Tools = append(target.Tools, TargetTool{
+			Name: references.ToolName,
+		})
+	}
+	o.targets = append(o.targets, target)
+	return nil
+}
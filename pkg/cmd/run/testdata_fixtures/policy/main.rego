@@ -0,0 +1,8 @@
+package main
+
+deny[msg] {
+  input.kind == "Deployment"
+  c := input.spec.template.spec.containers[_]
+  not c.resources.limits
+  msg := sprintf("container %s must have resource limits", [c.name])
+}
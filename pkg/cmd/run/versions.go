@@ -0,0 +1,65 @@
+package run
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/pkg/errors"
+)
+
+// kubernetesVersions returns the target kubernetes versions or a single empty version if none are configured
+func (o *Options) kubernetesVersions() []v1alpha1.KubernetesVersion {
+	versions := o.Settings.Spec.KubernetesVersions
+	if len(versions) == 0 {
+		return []v1alpha1.KubernetesVersion{{}}
+	}
+	return versions
+}
+
+// versionLocation returns a copy of the location labelled with the given kubernetes version
+func versionLocation(co *results.ResourceLocation, version string) *results.ResourceLocation {
+	if version == "" {
+		return co
+	}
+	answer := *co
+	answer.KubernetesVersion = version
+	answer.Description = co.Description + " kubernetes " + version
+	answer.Path = filepath.Join(co.Path, "kubernetes-"+version)
+	return &answer
+}
+
+// verifyResourcesForVersions verifies the resources against each of the target kubernetes versions
+func (o *Options) verifyResourcesForVersions(co *results.ResourceLocation, tests *v1alpha1.Tests) error {
+	for _, kv := range o.kubernetesVersions() {
+		vco := versionLocation(co, kv.Version)
+		err := o.verifyResources(vco, tests)
+		if err != nil {
+			return errors.Wrapf(err, "failed to verify %s", vco.Description)
+		}
+	}
+	return nil
+}
+
+// schemaVersion returns the kubernetes version to pass to schema validators which do not use the 'v' prefix
+func schemaVersion(version string) string {
+	return strings.TrimPrefix(version, "v")
+}
+
+// SetArgumentValue replaces any existing values of the argument with the given value
+func SetArgumentValue(args []string, flag string, optionName string, value string) []string {
+	var answer []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if (flag != "" && arg == "-"+flag) || arg == "--"+optionName {
+			i++
+			continue
+		}
+		if (flag != "" && strings.HasPrefix(arg, "-"+flag+"=")) || strings.HasPrefix(arg, "--"+optionName+"=") {
+			continue
+		}
+		answer = append(answer, arg)
+	}
+	return append(answer, "--"+optionName, value)
+}
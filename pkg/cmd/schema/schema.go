@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/schema"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cobras/helper"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cobras/templates"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/termcolor"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	info = termcolor.ColorInfo
+
+	cmdLong = templates.LongDesc(`
+		Displays the JSON Schema of the .jx/kube-test/settings.yaml file for use in editors and IDEs
+`)
+
+	cmdExample = templates.Examples(`
+		# displays the JSON Schema of the settings file
+		jx kube test schema
+
+		# saves the JSON Schema to a file
+		jx kube test schema --file kubetest.json
+	`)
+)
+
+// Options the options for the command
+type Options struct {
+	File string
+	Out  io.Writer
+}
+
+// NewCmdSchema creates a command object for the command
+func NewCmdSchema() (*cobra.Command, *Options) {
+	o := &Options{}
+
+	cmd := &cobra.Command{
+		Use:     "schema",
+		Short:   "Displays the JSON Schema of the settings file",
+		Long:    cmdLong,
+		Example: cmdExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := o.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&o.File, "file", "f", "", "the file to save the JSON Schema to. If not specified the schema is written to the standard output")
+	return cmd, o
+}
+
+// Run implements the command
+func (o *Options) Run() error {
+	if o.File != "" {
+		err := ioutil.WriteFile(o.File, []byte(schema.JSON), files.DefaultFileWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "failed to save file %s", o.File)
+		}
+		log.Logger().Infof("saved the JSON Schema to %s", info(o.File))
+		return nil
+	}
+	if o.Out == nil {
+		o.Out = os.Stdout
+	}
+	_, err := fmt.Fprint(o.Out, schema.JSON)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write the JSON Schema")
+	}
+	return nil
+}
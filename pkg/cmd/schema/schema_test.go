@@ -0,0 +1,34 @@
+package schema_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	cmdschema "github.com/jenkins-x-plugins/jx-kube-test/pkg/cmd/schema"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCmdSchema(t *testing.T) {
+	buf := &bytes.Buffer{}
+	_, o := cmdschema.NewCmdSchema()
+	o.Out = buf
+	err := o.Run()
+	require.NoError(t, err, "failed to run")
+	assert.Equal(t, schema.JSON, buf.String(), "schema output")
+
+	tmpDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	_, o = cmdschema.NewCmdSchema()
+	o.File = filepath.Join(tmpDir, "kubetest.json")
+	err = o.Run()
+	require.NoError(t, err, "failed to run")
+
+	data, err := ioutil.ReadFile(o.File)
+	require.NoError(t, err, "failed to load %s", o.File)
+	assert.Equal(t, schema.JSON, string(data), "schema file")
+}
@@ -0,0 +1,84 @@
+package deprecations
+
+// API a kubernetes API which is deprecated or removed
+type API struct {
+	// APIVersion the group and version of the API
+	APIVersion string
+
+	// Kind the kind of the resource
+	Kind string
+
+	// DeprecatedIn the kubernetes version the API was deprecated in
+	DeprecatedIn string
+
+	// RemovedIn the kubernetes version the API was removed in
+	RemovedIn string
+
+	// Replacement the API version to use instead if there is one
+	Replacement string
+}
+
+// APIs the deprecated and removed kubernetes APIs
+var APIs = []API{
+	// removed in 1.16
+	{APIVersion: "extensions/v1beta1", Kind: "DaemonSet", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1"},
+	{APIVersion: "extensions/v1beta1", Kind: "Deployment", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1"},
+	{APIVersion: "extensions/v1beta1", Kind: "ReplicaSet", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1"},
+	{APIVersion: "extensions/v1beta1", Kind: "NetworkPolicy", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "networking.k8s.io/v1"},
+	{APIVersion: "extensions/v1beta1", Kind: "PodSecurityPolicy", DeprecatedIn: "1.10", RemovedIn: "1.16", Replacement: "policy/v1beta1"},
+	{APIVersion: "apps/v1beta1", Kind: "Deployment", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1"},
+	{APIVersion: "apps/v1beta1", Kind: "StatefulSet", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1"},
+	{APIVersion: "apps/v1beta2", Kind: "DaemonSet", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1"},
+	{APIVersion: "apps/v1beta2", Kind: "Deployment", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1"},
+	{APIVersion: "apps/v1beta2", Kind: "ReplicaSet", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1"},
+	{APIVersion: "apps/v1beta2", Kind: "StatefulSet", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1"},
+
+	// removed in 1.22
+	{APIVersion: "admissionregistration.k8s.io/v1beta1", Kind: "MutatingWebhookConfiguration", DeprecatedIn: "1.16", RemovedIn: "1.22", Replacement: "admissionregistration.k8s.io/v1"},
+	{APIVersion: "admissionregistration.k8s.io/v1beta1", Kind: "ValidatingWebhookConfiguration", DeprecatedIn: "1.16", RemovedIn: "1.22", Replacement: "admissionregistration.k8s.io/v1"},
+	{APIVersion: "apiextensions.k8s.io/v1beta1", Kind: "CustomResourceDefinition", DeprecatedIn: "1.16", RemovedIn: "1.22", Replacement: "apiextensions.k8s.io/v1"},
+	{APIVersion: "apiregistration.k8s.io/v1beta1", Kind: "APIService", DeprecatedIn: "1.19", RemovedIn: "1.22", Replacement: "apiregistration.k8s.io/v1"},
+	{APIVersion: "certificates.k8s.io/v1beta1", Kind: "CertificateSigningRequest", DeprecatedIn: "1.19", RemovedIn: "1.22", Replacement: "certificates.k8s.io/v1"},
+	{APIVersion: "coordination.k8s.io/v1beta1", Kind: "Lease", DeprecatedIn: "1.19", RemovedIn: "1.22", Replacement: "coordination.k8s.io/v1"},
+	{APIVersion: "extensions/v1beta1", Kind: "Ingress", DeprecatedIn: "1.14", RemovedIn: "1.22", Replacement: "networking.k8s.io/v1"},
+	{APIVersion: "networking.k8s.io/v1beta1", Kind: "Ingress", DeprecatedIn: "1.19", RemovedIn: "1.22", Replacement: "networking.k8s.io/v1"},
+	{APIVersion: "networking.k8s.io/v1beta1", Kind: "IngressClass", DeprecatedIn: "1.19", RemovedIn: "1.22", Replacement: "networking.k8s.io/v1"},
+	{APIVersion: "rbac.authorization.k8s.io/v1alpha1", Kind: "ClusterRole", DeprecatedIn: "1.17", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1"},
+	{APIVersion: "rbac.authorization.k8s.io/v1alpha1", Kind: "ClusterRoleBinding", DeprecatedIn: "1.17", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1"},
+	{APIVersion: "rbac.authorization.k8s.io/v1alpha1", Kind: "Role", DeprecatedIn: "1.17", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1"},
+	{APIVersion: "rbac.authorization.k8s.io/v1alpha1", Kind: "RoleBinding", DeprecatedIn: "1.17", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1"},
+	{APIVersion: "rbac.authorization.k8s.io/v1beta1", Kind: "ClusterRole", DeprecatedIn: "1.17", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1"},
+	{APIVersion: "rbac.authorization.k8s.io/v1beta1", Kind: "ClusterRoleBinding", DeprecatedIn: "1.17", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1"},
+	{APIVersion: "rbac.authorization.k8s.io/v1beta1", Kind: "Role", DeprecatedIn: "1.17", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1"},
+	{APIVersion: "rbac.authorization.k8s.io/v1beta1", Kind: "RoleBinding", DeprecatedIn: "1.17", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1"},
+	{APIVersion: "scheduling.k8s.io/v1beta1", Kind: "PriorityClass", DeprecatedIn: "1.14", RemovedIn: "1.22", Replacement: "scheduling.k8s.io/v1"},
+	{APIVersion: "storage.k8s.io/v1beta1", Kind: "CSIDriver", DeprecatedIn: "1.19", RemovedIn: "1.22", Replacement: "storage.k8s.io/v1"},
+	{APIVersion: "storage.k8s.io/v1beta1", Kind: "CSINode", DeprecatedIn: "1.17", RemovedIn: "1.22", Replacement: "storage.k8s.io/v1"},
+	{APIVersion: "storage.k8s.io/v1beta1", Kind: "StorageClass", DeprecatedIn: "1.19", RemovedIn: "1.22", Replacement: "storage.k8s.io/v1"},
+	{APIVersion: "storage.k8s.io/v1beta1", Kind: "VolumeAttachment", DeprecatedIn: "1.19", RemovedIn: "1.22", Replacement: "storage.k8s.io/v1"},
+
+	// removed in 1.25
+	{APIVersion: "autoscaling/v2beta1", Kind: "HorizontalPodAutoscaler", DeprecatedIn: "1.22", RemovedIn: "1.25", Replacement: "autoscaling/v2"},
+	{APIVersion: "batch/v1beta1", Kind: "CronJob", DeprecatedIn: "1.21", RemovedIn: "1.25", Replacement: "batch/v1"},
+	{APIVersion: "discovery.k8s.io/v1beta1", Kind: "EndpointSlice", DeprecatedIn: "1.21", RemovedIn: "1.25", Replacement: "discovery.k8s.io/v1"},
+	{APIVersion: "events.k8s.io/v1beta1", Kind: "Event", DeprecatedIn: "1.19", RemovedIn: "1.25", Replacement: "events.k8s.io/v1"},
+	{APIVersion: "node.k8s.io/v1beta1", Kind: "RuntimeClass", DeprecatedIn: "1.20", RemovedIn: "1.25", Replacement: "node.k8s.io/v1"},
+	{APIVersion: "policy/v1beta1", Kind: "PodDisruptionBudget", DeprecatedIn: "1.21", RemovedIn: "1.25", Replacement: "policy/v1"},
+	{APIVersion: "policy/v1beta1", Kind: "PodSecurityPolicy", DeprecatedIn: "1.21", RemovedIn: "1.25"},
+
+	// removed in 1.26
+	{APIVersion: "autoscaling/v2beta2", Kind: "HorizontalPodAutoscaler", DeprecatedIn: "1.23", RemovedIn: "1.26", Replacement: "autoscaling/v2"},
+	{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta1", Kind: "FlowSchema", DeprecatedIn: "1.23", RemovedIn: "1.26", Replacement: "flowcontrol.apiserver.k8s.io/v1"},
+	{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta1", Kind: "PriorityLevelConfiguration", DeprecatedIn: "1.23", RemovedIn: "1.26", Replacement: "flowcontrol.apiserver.k8s.io/v1"},
+
+	// removed in 1.27
+	{APIVersion: "storage.k8s.io/v1beta1", Kind: "CSIStorageCapacity", DeprecatedIn: "1.24", RemovedIn: "1.27", Replacement: "storage.k8s.io/v1"},
+
+	// removed in 1.29
+	{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta2", Kind: "FlowSchema", DeprecatedIn: "1.26", RemovedIn: "1.29", Replacement: "flowcontrol.apiserver.k8s.io/v1"},
+	{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta2", Kind: "PriorityLevelConfiguration", DeprecatedIn: "1.26", RemovedIn: "1.29", Replacement: "flowcontrol.apiserver.k8s.io/v1"},
+
+	// removed in 1.32
+	{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta3", Kind: "FlowSchema", DeprecatedIn: "1.29", RemovedIn: "1.32", Replacement: "flowcontrol.apiserver.k8s.io/v1"},
+	{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta3", Kind: "PriorityLevelConfiguration", DeprecatedIn: "1.29", RemovedIn: "1.32", Replacement: "flowcontrol.apiserver.k8s.io/v1"},
+}
@@ -0,0 +1,118 @@
+package deprecations
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ToolName the name of the deprecated API check used in results
+	ToolName = "deprecations"
+
+	// CheckDeprecated the check ID for APIs which are deprecated in the target version
+	CheckDeprecated = "deprecated-api"
+
+	// CheckRemoved the check ID for APIs which are removed in the target version
+	CheckRemoved = "removed-api"
+)
+
+// Find returns the deprecated or removed API for the given api version and kind or nil if it is not deprecated
+func Find(apiVersion, kind string) *API {
+	for i := range APIs {
+		api := &APIs[i]
+		if api.APIVersion == apiVersion && api.Kind == kind {
+			return api
+		}
+	}
+	return nil
+}
+
+// Check checks the resources for any deprecated or removed APIs in the target kubernetes version.
+// If no target version is specified then any API which is deprecated in any version is reported
+func Check(resources []*manifests.Manifest, co *results.ResourceLocation, targetVersion string) ([]*results.Result, error) {
+	var answer []*results.Result
+	for _, m := range resources {
+		r := &results.Result{
+			Tool:      ToolName,
+			Location:  co,
+			Kind:      m.Kind(),
+			Name:      m.Name(),
+			Namespace: m.Namespace(),
+			File:      m.File,
+			CheckID:   CheckDeprecated,
+			Status:    results.StatusPassed,
+		}
+		answer = append(answer, r)
+
+		api := Find(m.Object.GetAPIVersion(), m.Kind())
+		if api == nil {
+			continue
+		}
+		removed, err := isAtLeast(targetVersion, api.RemovedIn)
+		if err != nil {
+			return nil, err
+		}
+		deprecated, err := isAtLeast(targetVersion, api.DeprecatedIn)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case targetVersion != "" && removed:
+			r.CheckID = CheckRemoved
+			r.Status = results.StatusFailed
+			r.Severity = results.SeverityError
+			r.Message = fmt.Sprintf("%s %s was removed in kubernetes %s", api.APIVersion, api.Kind, api.RemovedIn)
+		case targetVersion == "" || deprecated:
+			r.Status = results.StatusFailed
+			r.Severity = results.SeverityWarning
+			r.Message = fmt.Sprintf("%s %s is deprecated in kubernetes %s and removed in %s", api.APIVersion, api.Kind, api.DeprecatedIn, api.RemovedIn)
+		default:
+			continue
+		}
+		if api.Replacement != "" {
+			r.Message += fmt.Sprintf(". Use %s instead", api.Replacement)
+		}
+	}
+	return answer, nil
+}
+
+// isAtLeast returns true if the version is at least the minimum major and minor version
+func isAtLeast(version, minimum string) (bool, error) {
+	if version == "" {
+		return false, nil
+	}
+	major, minor, err := ParseVersion(version)
+	if err != nil {
+		return false, err
+	}
+	minMajor, minMinor, err := ParseVersion(minimum)
+	if err != nil {
+		return false, err
+	}
+	if major != minMajor {
+		return major > minMajor, nil
+	}
+	return minor >= minMinor, nil
+}
+
+// ParseVersion parses the major and minor version of a kubernetes version such as 'v1.21.3'
+func ParseVersion(version string) (int, int, error) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 {
+		return 0, 0, errors.Errorf("invalid kubernetes version %s", version)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "invalid major version in kubernetes version %s", version)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "invalid minor version in kubernetes version %s", version)
+	}
+	return major, minor, nil
+}
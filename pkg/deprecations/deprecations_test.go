@@ -0,0 +1,79 @@
+package deprecations_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/deprecations"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	resources, err := manifests.LoadFile(filepath.Join("test_data", "resources.yaml"))
+	require.NoError(t, err, "failed to load resources")
+	require.Len(t, resources, 3, "resources")
+
+	type expectedResult struct {
+		checkID  string
+		status   results.Status
+		severity results.Severity
+	}
+	passed := expectedResult{deprecations.CheckDeprecated, results.StatusPassed, ""}
+	deprecated := expectedResult{deprecations.CheckDeprecated, results.StatusFailed, "warning"}
+	removed := expectedResult{deprecations.CheckRemoved, results.StatusFailed, "error"}
+
+	testCases := []struct {
+		version  string
+		expected []expectedResult
+	}{
+		{
+			version:  "",
+			expected: []expectedResult{passed, deprecated, deprecated},
+		},
+		{
+			version:  "1.18.0",
+			expected: []expectedResult{passed, passed, passed},
+		},
+		{
+			version:  "1.21.1",
+			expected: []expectedResult{passed, deprecated, deprecated},
+		},
+		{
+			version:  "v1.22.0",
+			expected: []expectedResult{passed, removed, deprecated},
+		},
+		{
+			version:  "1.25",
+			expected: []expectedResult{passed, removed, removed},
+		},
+	}
+
+	for _, tc := range testCases {
+		got, err := deprecations.Check(resources, &results.ResourceLocation{}, tc.version)
+		require.NoError(t, err, "failed to check version %s", tc.version)
+		require.Len(t, got, len(tc.expected), "results for version %s", tc.version)
+
+		for i, e := range tc.expected {
+			r := got[i]
+			assert.Equal(t, e.checkID, r.CheckID, "check for result %d version %s", i, tc.version)
+			assert.Equal(t, e.status, r.Status, "status for result %d version %s", i, tc.version)
+			assert.Equal(t, e.severity, r.Severity, "severity for result %d version %s", i, tc.version)
+			if r.Failed() {
+				t.Logf("version %s: %s\n", tc.version, r.String())
+			}
+		}
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	major, minor, err := deprecations.ParseVersion("v1.22.3")
+	require.NoError(t, err, "failed to parse version")
+	assert.Equal(t, 1, major, "major")
+	assert.Equal(t, 22, minor, "minor")
+
+	_, _, err = deprecations.ParseVersion("latest")
+	assert.Error(t, err, "should fail to parse an invalid version")
+}
@@ -0,0 +1,209 @@
+package duplicates
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// ToolName the name of the duplicate resource check used in results
+	ToolName = "duplicates"
+
+	// CheckDuplicate the check ID for resources with the same API group, kind, namespace and name as another resource
+	CheckDuplicate = "duplicate"
+
+	// CheckConflictingNamespace the check ID for namespaced resources which are defined both with and without a
+	// namespace so that the namespace they end up in depends on how they are applied
+	CheckConflictingNamespace = "conflicting-namespace"
+
+	// CheckClusterScopedNamespace the check ID for cluster scoped resources which have a namespace
+	CheckClusterScopedNamespace = "cluster-scoped-namespace"
+)
+
+var (
+	// clusterScopedKinds the cluster scoped kubernetes resources as the kind qualified by the API group
+	clusterScopedKinds = []string{
+		"APIService.apiregistration.k8s.io",
+		"CSIDriver.storage.k8s.io",
+		"CSINode.storage.k8s.io",
+		"CertificateSigningRequest.certificates.k8s.io",
+		"ClusterRole.rbac.authorization.k8s.io",
+		"ClusterRoleBinding.rbac.authorization.k8s.io",
+		"CustomResourceDefinition.apiextensions.k8s.io",
+		"IngressClass.networking.k8s.io",
+		"MutatingWebhookConfiguration.admissionregistration.k8s.io",
+		"Namespace",
+		"Node",
+		"PersistentVolume",
+		"PodSecurityPolicy.extensions",
+		"PodSecurityPolicy.policy",
+		"PriorityClass.scheduling.k8s.io",
+		"RuntimeClass.node.k8s.io",
+		"StorageClass.storage.k8s.io",
+		"ValidatingWebhookConfiguration.admissionregistration.k8s.io",
+		"VolumeAttachment.storage.k8s.io",
+	}
+)
+
+// Check checks the resources for resources which are defined more than once, namespaced resources which are defined
+// both with and without a namespace and cluster scoped resources which have a namespace.
+//
+// The scope of a resource is decided by its kind and API group. The built in kubernetes kinds and the kinds of any
+// CustomResourceDefinitions with the Cluster scope in the resources are known. Any other cluster scoped custom
+// resources, such as those whose CustomResourceDefinition is installed separately, must be given in the kinds either
+// as 'Kind.group' or as 'Kind' to match the kind in any API group. Otherwise they are treated as namespaced
+func Check(resources []*manifests.Manifest, co *results.ResourceLocation, kinds []string) []*results.Result {
+	c := &checker{
+		location:      co,
+		clusterScoped: map[string]bool{},
+		anyGroup:      map[string]bool{},
+	}
+	for _, k := range clusterScopedKinds {
+		c.clusterScoped[k] = true
+	}
+	for _, k := range kinds {
+		if strings.Contains(k, ".") {
+			c.clusterScoped[k] = true
+		} else {
+			c.anyGroup[k] = true
+		}
+	}
+	for _, m := range resources {
+		if m.Kind() != "CustomResourceDefinition" {
+			continue
+		}
+		scope, _, _ := unstructured.NestedString(m.Object.Object, "spec", "scope")
+		group, _, _ := unstructured.NestedString(m.Object.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(m.Object.Object, "spec", "names", "kind")
+		if scope == "Cluster" && kind != "" {
+			c.clusterScoped[qualifiedKind(group, kind)] = true
+		}
+	}
+
+	identities := map[string][]*manifests.Manifest{}
+	names := map[string][]*manifests.Manifest{}
+	for _, m := range resources {
+		identities[c.identity(m)] = append(identities[c.identity(m)], m)
+		if !c.isClusterScoped(m) {
+			names[nameKey(m)] = append(names[nameKey(m)], m)
+		}
+	}
+
+	for _, m := range resources {
+		var messages []string
+		for _, other := range identities[c.identity(m)] {
+			if other != m {
+				messages = append(messages, fmt.Sprintf("the resource is also defined in %s", location(m, other)))
+			}
+		}
+		c.addResults(m, CheckDuplicate, messages)
+
+		if c.isClusterScoped(m) {
+			message := ""
+			if m.Namespace() != "" {
+				message = fmt.Sprintf("the cluster scoped %s %s has the namespace %s", m.Kind(), m.Name(), m.Namespace())
+			}
+			c.addResult(m, CheckClusterScopedNamespace, message)
+			continue
+		}
+
+		messages = nil
+		for _, other := range names[nameKey(m)] {
+			if m.Namespace() == "" && other.Namespace() != "" {
+				messages = append(messages, fmt.Sprintf("the resource has no namespace but is also defined in the namespace %s in %s", other.Namespace(), location(m, other)))
+			} else if m.Namespace() != "" && other.Namespace() == "" {
+				messages = append(messages, fmt.Sprintf("the resource is also defined without a namespace in %s", location(m, other)))
+			}
+		}
+		c.addResults(m, CheckConflictingNamespace, messages)
+	}
+	return c.results
+}
+
+// checker checks a set of resources for duplicates
+type checker struct {
+	location      *results.ResourceLocation
+	clusterScoped map[string]bool
+	anyGroup      map[string]bool
+	results       []*results.Result
+}
+
+// addResult adds a result for the check of the resource which failed if there is a message
+func (c *checker) addResult(m *manifests.Manifest, checkID, message string) {
+	r := &results.Result{
+		Tool:      ToolName,
+		Location:  c.location,
+		Kind:      m.Kind(),
+		Name:      m.Name(),
+		Namespace: m.Namespace(),
+		File:      m.File,
+		CheckID:   checkID,
+		Status:    results.StatusPassed,
+	}
+	if message != "" {
+		r.Status = results.StatusFailed
+		r.Severity = results.SeverityError
+		r.Message = message
+	}
+	c.results = append(c.results, r)
+}
+
+// addResults adds a failed result for each message or a passed result if there are none
+func (c *checker) addResults(m *manifests.Manifest, checkID string, messages []string) {
+	if len(messages) == 0 {
+		c.addResult(m, checkID, "")
+		return
+	}
+	for _, message := range messages {
+		c.addResult(m, checkID, message)
+	}
+}
+
+// isClusterScoped returns true if the resource is cluster scoped
+func (c *checker) isClusterScoped(m *manifests.Manifest) bool {
+	return c.anyGroup[m.Kind()] || c.clusterScoped[qualifiedKind(apiGroup(m.Object.GetAPIVersion()), m.Kind())]
+}
+
+// identity returns the API group, kind, namespace and name which identify the resource in a cluster. The namespace
+// of cluster scoped resources is ignored as it is ignored when they are applied
+func (c *checker) identity(m *manifests.Manifest) string {
+	ns := m.Namespace()
+	if c.isClusterScoped(m) {
+		ns = ""
+	}
+	return nameKey(m) + "/" + ns
+}
+
+// nameKey returns the API group, kind and name of the resource
+func nameKey(m *manifests.Manifest) string {
+	return apiGroup(m.Object.GetAPIVersion()) + "/" + m.Kind() + "/" + m.Name()
+}
+
+// apiGroup returns the API group of the API version. Different versions of the same group are the same resource
+func apiGroup(apiVersion string) string {
+	i := strings.LastIndex(apiVersion, "/")
+	if i < 0 {
+		return ""
+	}
+	return apiVersion[:i]
+}
+
+// qualifiedKind returns the kind qualified by the API group unless it is in the core group
+func qualifiedKind(group, kind string) string {
+	if group == "" {
+		return kind
+	}
+	return kind + "." + group
+}
+
+// location returns the file of the other resource including the document index if it is in the same file
+func location(m, other *manifests.Manifest) string {
+	if m.File == other.File {
+		return fmt.Sprintf("document %d of %s", other.Index, other.File)
+	}
+	return other.File
+}
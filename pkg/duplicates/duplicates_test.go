@@ -0,0 +1,63 @@
+package duplicates_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/duplicates"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	resources, err := manifests.LoadDir("test_data")
+	require.NoError(t, err, "failed to load resources")
+
+	items := duplicates.Check(resources, nil, []string{"Team"})
+
+	var actual []string
+	for _, r := range items {
+		if r.Failed() {
+			actual = append(actual, r.String())
+		}
+	}
+	assert.Equal(t, []string{
+		"duplicates failed cluster-scoped-namespace on ClusterIssuer jx/letsencrypt: the cluster scoped ClusterIssuer letsencrypt has the namespace jx",
+		"duplicates failed cluster-scoped-namespace on Team jx/core: the cluster scoped Team core has the namespace jx",
+		"duplicates failed conflicting-namespace on ConfigMap jx/config: the resource is also defined without a namespace in test_data/namespaces/jx/web.yaml",
+		"duplicates failed duplicate on Deployment jx/web: the resource is also defined in test_data/namespaces/jx/web.yaml",
+		"duplicates failed duplicate on Deployment jx/web: the resource is also defined in test_data/namespaces/jx/web-copy.yaml",
+		"duplicates failed duplicate on Service jx/web: the resource is also defined in document 3 of test_data/namespaces/jx/web.yaml",
+		"duplicates failed conflicting-namespace on ConfigMap config: the resource has no namespace but is also defined in the namespace jx in test_data/namespaces/jx/config.yaml",
+		"duplicates failed duplicate on Service jx/web: the resource is also defined in document 1 of test_data/namespaces/jx/web.yaml",
+		"duplicates failed duplicate on Deployment other/api: the resource is also defined in document 1 of test_data/namespaces/other/api.yaml",
+		"duplicates failed duplicate on Deployment other/api: the resource is also defined in document 0 of test_data/namespaces/other/api.yaml",
+	}, actual, "failed results")
+	assert.Len(t, items, 28, "results")
+
+	for _, r := range items {
+		if r.Kind == "Node" {
+			assert.NotEqual(t, duplicates.CheckClusterScopedNamespace, r.CheckID, "should not treat the Node kind of another API group as cluster scoped")
+		}
+	}
+
+	items = duplicates.Check(resources, nil, nil)
+	for _, r := range items {
+		if r.Kind == "Team" {
+			assert.False(t, r.Failed(), "should not treat Team as cluster scoped %s", r.String())
+		}
+	}
+
+	for _, kinds := range [][]string{{"Node"}, {"Node.example.io"}} {
+		items = duplicates.Check(resources, nil, kinds)
+		var failed []string
+		for _, r := range items {
+			if r.Kind == "Node" && r.Failed() {
+				failed = append(failed, r.String())
+			}
+		}
+		assert.Equal(t, []string{
+			"duplicates failed cluster-scoped-namespace on Node other/worker: the cluster scoped Node worker has the namespace other",
+		}, failed, "failed Node results for kinds %v", kinds)
+	}
+}
@@ -0,0 +1,98 @@
+package manifests
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Manifest a kubernetes resource loaded from a YAML file
+type Manifest struct {
+	// File the file the resource was loaded from
+	File string
+
+	// Index the index of the document in the file
+	Index int
+
+	// Object the resource
+	Object *unstructured.Unstructured
+}
+
+// Kind returns the kind of the resource
+func (m *Manifest) Kind() string {
+	return m.Object.GetKind()
+}
+
+// Name returns the name of the resource
+func (m *Manifest) Name() string {
+	return m.Object.GetName()
+}
+
+// Namespace returns the namespace of the resource
+func (m *Manifest) Namespace() string {
+	return m.Object.GetNamespace()
+}
+
+// LoadFile loads all of the kubernetes resources in the given YAML file
+func LoadFile(path string) ([]*Manifest, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read file %s", path)
+	}
+	var answer []*Manifest
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for i := 0; ; i++ {
+		obj := &unstructured.Unstructured{}
+		err = decoder.Decode(&obj.Object)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return answer, errors.Wrapf(err, "failed to parse YAML document %d in file %s", i, path)
+		}
+		if len(obj.Object) == 0 || obj.GetKind() == "" {
+			continue
+		}
+		answer = append(answer, &Manifest{
+			File:   path,
+			Index:  i,
+			Object: obj,
+		})
+	}
+	return answer, nil
+}
+
+// LoadDir loads all of the kubernetes resources in the YAML files in the given directory tree
+func LoadDir(dir string) ([]*Manifest, error) {
+	var answer []*Manifest
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !IsYAMLFile(path) {
+			return nil
+		}
+		resources, err := LoadFile(path)
+		if err != nil {
+			return err
+		}
+		answer = append(answer, resources...)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load resources in dir %s", dir)
+	}
+	return answer, nil
+}
+
+// IsYAMLFile returns true if the file name is a YAML file
+func IsYAMLFile(path string) bool {
+	return strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")
+}
@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"github.com/pkg/errors"
 	"os"
+	"path/filepath"
 	"strings"
 
 	jenkinsv1 "github.com/jenkins-x/jx-api/v4/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/extensions"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/homedir"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -19,13 +21,37 @@ func PluginBinDir() (string, error) {
 
 // PluginBinDirFunc uses a function for looking up env vars for easier testing
 func PluginBinDirFunc(fn func(string) string) (string, error) {
+	return homedir.PluginBinDir(homeDirEnv(fn), ".jx")
+}
+
+// CacheDir returns the dir used to cache test results which is in the same home dir as the plugins
+func CacheDir() (string, error) {
+	return CacheDirFunc(os.Getenv)
+}
+
+// CacheDirFunc uses a function for looking up env vars for easier testing
+func CacheDirFunc(fn func(string) string) (string, error) {
+	cacheDir, err := homedir.CacheDir(homeDirEnv(fn), ".jx")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find the cache dir")
+	}
+	path := filepath.Join(cacheDir, "kube-test")
+	err = os.MkdirAll(path, files.DefaultDirWritePermissions)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create dir %s", path)
+	}
+	return path, nil
+}
+
+// homeDirEnv returns the value of the first env var which overrides the home dir
+func homeDirEnv(fn func(string) string) string {
 	for _, e := range []string{"JX_GITOPS_HOME", "JX3_HOME", "JX_HOME"} {
 		v := fn(e)
 		if v != "" {
-			return homedir.PluginBinDir(v, ".jx")
+			return v
 		}
 	}
-	return homedir.PluginBinDir("", ".jx")
+	return ""
 }
 
 // GetConftestBinary returns the path to the locally installed kube-score extension
@@ -71,6 +97,44 @@ func CreateConftestPlugin(version string) jenkinsv1.Plugin {
 	return plugin
 }
 
+// GetKubeconformBinary returns the path to the locally installed kubeconform extension
+func GetKubeconformBinary(version string) (string, error) {
+	if version == "" {
+		version = KubeconformVersion
+	}
+	pluginBinDir, err := PluginBinDir()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find plugin home dir")
+	}
+	plugin := CreateKubeconformPlugin(version)
+	return extensions.EnsurePluginInstalled(plugin, pluginBinDir)
+}
+
+// CreateKubeconformPlugin creates the kubeconform plugin
+func CreateKubeconformPlugin(version string) jenkinsv1.Plugin {
+	binaries := extensions.CreateBinaries(func(p extensions.Platform) string {
+		ext := ".tar.gz"
+		if p.IsWindows() {
+			ext = ".zip"
+		}
+		return fmt.Sprintf("https://github.com/yannh/kubeconform/releases/download/v%s/kubeconform-%s-%s%s", version, strings.ToLower(p.Goos), strings.ToLower(p.Goarch), ext)
+	})
+
+	plugin := jenkinsv1.Plugin{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: KubeconformPluginName,
+		},
+		Spec: jenkinsv1.PluginSpec{
+			SubCommand:  "kubeconform",
+			Binaries:    binaries,
+			Description: "kubeconform binary",
+			Name:        KubeconformPluginName,
+			Version:     version,
+		},
+	}
+	return plugin
+}
+
 // GetKubeScoreBinary returns the path to the locally installed kube-score extension
 func GetKubeScoreBinary(version string) (string, error) {
 	if version == "" {
@@ -142,6 +206,41 @@ func CreateKubevalPlugin(version string) jenkinsv1.Plugin {
 	return plugin
 }
 
+// GetKustomizeBinary returns the path to the locally installed kustomize extension
+func GetKustomizeBinary(version string) (string, error) {
+	if version == "" {
+		version = KustomizeVersion
+	}
+	pluginBinDir, err := PluginBinDir()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find plugin home dir")
+	}
+	plugin := CreateKustomizePlugin(version)
+	return extensions.EnsurePluginInstalled(plugin, pluginBinDir)
+}
+
+// CreateKustomizePlugin creates the kustomize plugin
+func CreateKustomizePlugin(version string) jenkinsv1.Plugin {
+	binaries := extensions.CreateBinaries(func(p extensions.Platform) string {
+		ext := ".tar.gz"
+		return fmt.Sprintf("https://github.com/kubernetes-sigs/kustomize/releases/download/kustomize%%2Fv%s/kustomize_v%s_%s_%s%s", version, version, strings.ToLower(p.Goos), strings.ToLower(p.Goarch), ext)
+	})
+
+	plugin := jenkinsv1.Plugin{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: KustomizePluginName,
+		},
+		Spec: jenkinsv1.PluginSpec{
+			SubCommand:  "kustomize",
+			Binaries:    binaries,
+			Description: "kustomize binary",
+			Name:        KustomizePluginName,
+			Version:     version,
+		},
+	}
+	return plugin
+}
+
 // GetPolarisBinary returns the path to the locally installed kube-score extension
 func GetPolarisBinary(version string) (string, error) {
 	if version == "" {
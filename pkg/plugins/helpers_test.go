@@ -1,12 +1,30 @@
 package plugins_test
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	"github.com/jenkins-x-plugins/jx-kube-test/pkg/plugins"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func TestCacheDir(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err, "failed to create temp dir")
+
+	dir, err := plugins.CacheDirFunc(func(name string) string {
+		if name == "JX_HOME" {
+			return tmpDir
+		}
+		return ""
+	})
+	require.NoError(t, err, "failed to find cache dir")
+	assert.Equal(t, filepath.Join(tmpDir, "cache", "kube-test"), dir, "cache dir")
+	assert.DirExists(t, dir, "should have created the cache dir")
+}
+
 func TestConftestPlugin(t *testing.T) {
 	t.Parallel()
 
@@ -52,6 +70,51 @@ func TestConftestPlugin(t *testing.T) {
 	assert.True(t, foundWindows, "did not find a windows binary in the plugin %#v", plugin)
 }
 
+func TestKubeconformPlugin(t *testing.T) {
+	t.Parallel()
+
+	plugin := plugins.CreateKubeconformPlugin(plugins.KubeconformVersion)
+
+	assert.Equal(t, plugins.KubeconformPluginName, plugin.Name, "plugin.Name")
+	assert.Equal(t, plugins.KubeconformPluginName, plugin.Spec.Name, "plugin.Spec.Name")
+
+	foundLinux := false
+	foundMac := false
+	foundWindows := false
+	foundArm := false
+	for _, b := range plugin.Spec.Binaries {
+		switch b.Goarch {
+		case "arm64":
+			switch b.Goos {
+			case "Linux":
+				foundArm = true
+				assert.Equal(t, "https://github.com/yannh/kubeconform/releases/download/v"+plugins.KubeconformVersion+"/kubeconform-linux-arm64.tar.gz", b.URL, "URL for linux arm binary")
+				t.Logf("found linux binary URL %s", b.URL)
+			}
+
+		case "amd64":
+			switch b.Goos {
+			case "Darwin":
+				foundMac = true
+				assert.Equal(t, "https://github.com/yannh/kubeconform/releases/download/v"+plugins.KubeconformVersion+"/kubeconform-darwin-amd64.tar.gz", b.URL, "URL for mac binary")
+				t.Logf("found mac binary URL %s", b.URL)
+			case "Linux":
+				foundLinux = true
+				assert.Equal(t, "https://github.com/yannh/kubeconform/releases/download/v"+plugins.KubeconformVersion+"/kubeconform-linux-amd64.tar.gz", b.URL, "URL for linux binary")
+				t.Logf("found linux binary URL %s", b.URL)
+			case "Windows":
+				foundWindows = true
+				assert.Equal(t, "https://github.com/yannh/kubeconform/releases/download/v"+plugins.KubeconformVersion+"/kubeconform-windows-amd64.zip", b.URL, "URL for windows binary")
+				t.Logf("found windows binary URL %s", b.URL)
+			}
+		}
+	}
+	assert.True(t, foundArm, "did not find an arm linux binary in the plugin %#v", plugin)
+	assert.True(t, foundLinux, "did not find a linux binary in the plugin %#v", plugin)
+	assert.True(t, foundMac, "did not find a mac binary in the plugin %#v", plugin)
+	assert.True(t, foundWindows, "did not find a windows binary in the plugin %#v", plugin)
+}
+
 func TestKubeScorePlugin(t *testing.T) {
 	t.Parallel()
 
@@ -146,6 +209,51 @@ func TestKubevalPlugin(t *testing.T) {
 	assert.True(t, foundWindows, "did not find a windows binary in the plugin %#v", plugin)
 }
 
+func TestKustomizePlugin(t *testing.T) {
+	t.Parallel()
+
+	plugin := plugins.CreateKustomizePlugin(plugins.KustomizeVersion)
+
+	assert.Equal(t, plugins.KustomizePluginName, plugin.Name, "plugin.Name")
+	assert.Equal(t, plugins.KustomizePluginName, plugin.Spec.Name, "plugin.Spec.Name")
+
+	foundLinux := false
+	foundMac := false
+	foundWindows := false
+	foundArm := false
+	for _, b := range plugin.Spec.Binaries {
+		switch b.Goarch {
+		case "arm64":
+			switch b.Goos {
+			case "Linux":
+				foundArm = true
+				assert.Equal(t, "https://github.com/kubernetes-sigs/kustomize/releases/download/kustomize%2Fv"+plugins.KustomizeVersion+"/kustomize_v"+plugins.KustomizeVersion+"_linux_arm64.tar.gz", b.URL, "URL for linux arm binary")
+				t.Logf("found linux binary URL %s", b.URL)
+			}
+
+		case "amd64":
+			switch b.Goos {
+			case "Darwin":
+				foundMac = true
+				assert.Equal(t, "https://github.com/kubernetes-sigs/kustomize/releases/download/kustomize%2Fv"+plugins.KustomizeVersion+"/kustomize_v"+plugins.KustomizeVersion+"_darwin_amd64.tar.gz", b.URL, "URL for mac binary")
+				t.Logf("found mac binary URL %s", b.URL)
+			case "Linux":
+				foundLinux = true
+				assert.Equal(t, "https://github.com/kubernetes-sigs/kustomize/releases/download/kustomize%2Fv"+plugins.KustomizeVersion+"/kustomize_v"+plugins.KustomizeVersion+"_linux_amd64.tar.gz", b.URL, "URL for linux binary")
+				t.Logf("found linux binary URL %s", b.URL)
+			case "Windows":
+				foundWindows = true
+				assert.Equal(t, "https://github.com/kubernetes-sigs/kustomize/releases/download/kustomize%2Fv"+plugins.KustomizeVersion+"/kustomize_v"+plugins.KustomizeVersion+"_windows_amd64.tar.gz", b.URL, "URL for windows binary")
+				t.Logf("found windows binary URL %s", b.URL)
+			}
+		}
+	}
+	assert.True(t, foundArm, "did not find an arm linux binary in the plugin %#v", plugin)
+	assert.True(t, foundLinux, "did not find a linux binary in the plugin %#v", plugin)
+	assert.True(t, foundMac, "did not find a mac binary in the plugin %#v", plugin)
+	assert.True(t, foundWindows, "did not find a windows binary in the plugin %#v", plugin)
+}
+
 func TestPolarisPlugin(t *testing.T) {
 	t.Parallel()
 
@@ -9,6 +9,18 @@ const (
 	// ConftestVersion the default version of conftest to use
 	ConftestVersion = "0.24.0"
 
+	// KubeconformPluginName the default name of the kubeconform plugin
+	KubeconformPluginName = "kubeconform"
+
+	// KubeconformVersion the default version of kubeconform to use
+	KubeconformVersion = "0.4.7"
+
+	// KustomizePluginName the default name of the kustomize plugin
+	KustomizePluginName = "kustomize"
+
+	// KustomizeVersion the default version of kustomize to use
+	KustomizeVersion = "4.1.3"
+
 	// KubeScorePluginName the default name of the kube-score plugin
 	KubeScorePluginName = "kube-score"
 
@@ -35,5 +47,7 @@ var (
 		CreatePolarisPlugin(KubeScoreVersion),
 		CreateKubeScorePlugin(KubeScoreVersion),
 		CreateKubevalPlugin(KubevalVersion),
+		CreateKubeconformPlugin(KubeconformVersion),
+		CreateKustomizePlugin(KustomizeVersion),
 	}
 )
@@ -0,0 +1,245 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/loader"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/storage/inmem"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ToolName the name of the built in Rego policy evaluation used in results
+	ToolName = "rego"
+
+	// DefaultPolicyDir the default directory of the policies which is the same as conftest
+	DefaultPolicyDir = "policy"
+
+	// DefaultNamespace the default package of the policies which is the same as conftest
+	DefaultNamespace = "main"
+)
+
+var (
+	// failureRules matches the names of the conftest rules which fail the resource
+	failureRules = regexp.MustCompile("^(deny|violation)(_[a-zA-Z0-9]+)*$")
+
+	// warningRules matches the names of the conftest rules which warn about the resource
+	warningRules = regexp.MustCompile("^warn(_[a-zA-Z0-9]+)*$")
+)
+
+// Engine evaluates the conftest compatible rules of the Rego policies against kubernetes resources
+type Engine struct {
+	queries []*query
+}
+
+// query a prepared query of a rule in a namespace
+type query struct {
+	name     string
+	severity results.Severity
+	prepared rego.PreparedEvalQuery
+}
+
+// Load loads and compiles the policies, bundles and data of the test preparing a query for each deny, violation and
+// warn rule in the namespaces of the test
+func Load(ctx context.Context, t *v1alpha1.RegoTest) (*Engine, error) {
+	policies := t.Policies
+	if len(policies) == 0 && len(t.Bundles) == 0 {
+		policies = []string{DefaultPolicyDir}
+	}
+	modules := map[string]*ast.Module{}
+	documents := map[string]interface{}{}
+	if len(policies) > 0 {
+		result, err := loader.AllRegos(policies)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load the policies in %s", strings.Join(policies, ", "))
+		}
+		for name, m := range result.ParsedModules() {
+			modules[name] = m
+		}
+	}
+	for _, path := range t.Bundles {
+		b, err := loader.NewFileLoader().AsBundle(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load the bundle %s", path)
+		}
+		for _, m := range b.Modules {
+			modules[filepath.Join(path, m.Path)] = m.Parsed
+		}
+		err = mergeDocuments(documents, b.Data, "")
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to merge the data of bundle %s", path)
+		}
+	}
+	if len(t.Data) > 0 {
+		result, err := loader.NewFileLoader().Filtered(t.Data, func(path string, info os.FileInfo, depth int) bool {
+			return !info.IsDir() && filepath.Ext(path) == ".rego"
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load the data in %s", strings.Join(t.Data, ", "))
+		}
+		err = mergeDocuments(documents, result.Documents, "")
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to merge the data")
+		}
+	}
+	if len(modules) == 0 {
+		return nil, errors.Errorf("no Rego policies found in %s", strings.Join(append(policies, t.Bundles...), ", "))
+	}
+
+	compiler := ast.NewCompiler()
+	compiler.Compile(modules)
+	if compiler.Failed() {
+		return nil, errors.Wrapf(compiler.Errors, "failed to compile the policies")
+	}
+	store := inmem.NewFromObject(documents)
+
+	namespaces := t.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{DefaultNamespace}
+	}
+	queries := map[string]results.Severity{}
+	for _, m := range compiler.Modules {
+		namespace := strings.TrimPrefix(m.Package.Path.String(), "data.")
+		if !t.AllNamespaces && !contains(namespaces, namespace) {
+			continue
+		}
+		for _, r := range m.Rules {
+			name := r.Head.Name.String()
+			switch {
+			case failureRules.MatchString(name):
+				queries["data."+namespace+"."+name] = results.SeverityError
+			case warningRules.MatchString(name):
+				queries["data."+namespace+"."+name] = results.SeverityWarning
+			}
+		}
+	}
+
+	e := &Engine{}
+	for name, severity := range queries {
+		prepared, err := rego.New(rego.Query(name), rego.Compiler(compiler), rego.Store(store)).PrepareForEval(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to prepare query %s", name)
+		}
+		e.queries = append(e.queries, &query{
+			name:     name,
+			severity: severity,
+			prepared: prepared,
+		})
+	}
+	sort.Slice(e.queries, func(i, j int) bool {
+		return e.queries[i].name < e.queries[j].name
+	})
+	return e, nil
+}
+
+// Check evaluates the rules against each resource. The query of each rule is used as the check ID like conftest
+func (e *Engine) Check(ctx context.Context, resources []*manifests.Manifest, co *results.ResourceLocation) ([]*results.Result, error) {
+	var answer []*results.Result
+	for _, m := range resources {
+		for _, q := range e.queries {
+			rs, err := q.prepared.Eval(ctx, rego.EvalInput(m.Object.Object))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to evaluate %s on %s in file %s", q.name, m.Name(), m.File)
+			}
+			newResult := func(status results.Status, severity results.Severity, message string) *results.Result {
+				return &results.Result{
+					Tool:      ToolName,
+					Location:  co,
+					Kind:      m.Kind(),
+					Name:      m.Name(),
+					Namespace: m.Namespace(),
+					File:      m.File,
+					CheckID:   q.name,
+					Status:    status,
+					Severity:  severity,
+					Message:   message,
+				}
+			}
+
+			messages := ruleMessages(rs)
+			for _, message := range messages {
+				answer = append(answer, newResult(results.StatusFailed, q.severity, message))
+			}
+			if len(messages) == 0 {
+				answer = append(answer, newResult(results.StatusPassed, "", ""))
+			}
+		}
+	}
+	return answer, nil
+}
+
+// ruleMessages returns the messages of the values of a rule which can be a set of strings or objects with a msg
+// like conftest or a boolean
+func ruleMessages(rs rego.ResultSet) []string {
+	var answer []string
+	for _, r := range rs {
+		for _, expr := range r.Expressions {
+			switch v := expr.Value.(type) {
+			case bool:
+				if v {
+					answer = append(answer, expr.Text)
+				}
+			case []interface{}:
+				for _, item := range v {
+					answer = append(answer, message(item))
+				}
+			}
+		}
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+// message returns the message of a value of a rule
+func message(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		if msg, ok := v["msg"].(string); ok {
+			return msg
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// mergeDocuments merges the src data documents into the dst documents failing if the same value is defined twice
+func mergeDocuments(dst, src map[string]interface{}, path string) error {
+	for k, v := range src {
+		existing, ok := dst[k]
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		existingMap, ok1 := existing.(map[string]interface{})
+		srcMap, ok2 := v.(map[string]interface{})
+		if !ok1 || !ok2 {
+			return errors.Errorf("the data %s%s is defined more than once", path, k)
+		}
+		err := mergeDocuments(existingMap, srcMap, path+k+".")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
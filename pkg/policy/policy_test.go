@@ -0,0 +1,102 @@
+package policy_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/policy"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	resources, err := manifests.LoadFile(filepath.Join("test_data", "resources.yaml"))
+	require.NoError(t, err, "failed to load resources")
+	require.Len(t, resources, 3, "resources")
+
+	policyDir := filepath.Join("test_data", "policy")
+	dataDir := filepath.Join("test_data", "data")
+	bundleDir := filepath.Join("test_data", "bundle")
+
+	testCases := []struct {
+		name     string
+		test     v1alpha1.RegoTest
+		expected []string
+	}{
+		{
+			name: "main namespace",
+			test: v1alpha1.RegoTest{
+				Policies: []string{policyDir},
+				Data:     []string{dataDir},
+			},
+			expected: []string{
+				"rego failed data.main.deny on Deployment root: root must not run as root",
+				"rego passed data.main.warn_labels on Deployment root",
+				"rego passed data.main.deny on Deployment nonroot",
+				"rego failed data.main.warn_labels on Deployment nonroot: nonroot should have a team label",
+				"rego passed data.main.deny on Service svc",
+				"rego passed data.main.warn_labels on Service svc",
+			},
+		},
+		{
+			name: "all namespaces",
+			test: v1alpha1.RegoTest{
+				Policies:      []string{policyDir},
+				Data:          []string{dataDir},
+				AllNamespaces: true,
+			},
+			expected: []string{
+				"rego passed data.kubernetes.violation on Deployment root",
+				"rego failed data.main.deny on Deployment root: root must not run as root",
+				"rego passed data.main.warn_labels on Deployment root",
+				"rego passed data.kubernetes.violation on Deployment nonroot",
+				"rego passed data.main.deny on Deployment nonroot",
+				"rego failed data.main.warn_labels on Deployment nonroot: nonroot should have a team label",
+				"rego failed data.kubernetes.violation on Service svc: svc must not use a NodePort",
+				"rego passed data.main.deny on Service svc",
+				"rego passed data.main.warn_labels on Service svc",
+			},
+		},
+		{
+			name: "bundle",
+			test: v1alpha1.RegoTest{
+				Bundles:    []string{bundleDir},
+				Namespaces: []string{"registries"},
+			},
+			expected: []string{
+				"rego failed data.registries.deny on Deployment root: image docker.io/root:1.0.0 is not from an allowed registry",
+				"rego passed data.registries.deny on Deployment nonroot",
+				"rego passed data.registries.deny on Service svc",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		e, err := policy.Load(ctx, &tc.test)
+		require.NoError(t, err, "failed to load policies for %s", tc.name)
+
+		items, err := e.Check(ctx, resources, nil)
+		require.NoError(t, err, "failed to check %s", tc.name)
+
+		var actual []string
+		for _, r := range items {
+			actual = append(actual, r.String())
+			if r.Failed() {
+				expected := results.SeverityError
+				if filepath.Ext(r.CheckID) == ".warn_labels" {
+					expected = results.SeverityWarning
+				}
+				assert.Equal(t, expected, r.Severity, "severity of %s for %s", r.String(), tc.name)
+			}
+		}
+		assert.Equal(t, tc.expected, actual, "results for %s", tc.name)
+	}
+
+	_, err = policy.Load(ctx, &v1alpha1.RegoTest{Policies: []string{filepath.Join("test_data", "data")}})
+	require.Error(t, err, "should fail if there are no policies")
+}
@@ -0,0 +1,11 @@
+package registries
+
+deny[msg] {
+  c := input.spec.template.spec.containers[_]
+  not trusted(c.image)
+  msg := sprintf("image %s is not from an allowed registry", [c.image])
+}
+
+trusted(image) {
+  startswith(image, data.registries.allowed[_])
+}
@@ -0,0 +1,7 @@
+package kubernetes
+
+violation[msg] {
+  input.kind == "Service"
+  input.spec.type == "NodePort"
+  msg := sprintf("%s must not use a NodePort", [input.metadata.name])
+}
@@ -0,0 +1,16 @@
+package main
+
+deny[msg] {
+  input.kind == "Deployment"
+  not input.spec.template.spec.securityContext.runAsNonRoot
+  msg := sprintf("%s must not run as root", [input.metadata.name])
+}
+
+warn_labels[msg] {
+  not valid_team
+  msg := {"msg": sprintf("%s should have a team label", [input.metadata.name])}
+}
+
+valid_team {
+  input.metadata.labels.team == data.teams[_]
+}
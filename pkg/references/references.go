@@ -0,0 +1,386 @@
+package references
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// ToolName the name of the reference integrity check used in results
+	ToolName = "references"
+
+	// CheckServiceSelector the check ID for Services whose selector does not match any pod template
+	CheckServiceSelector = "service-selector"
+
+	// CheckIngressBackend the check ID for Ingress backends which refer to a missing Service or port
+	CheckIngressBackend = "ingress-backend"
+
+	// CheckPodReference the check ID for ConfigMaps, Secrets, PersistentVolumeClaims or ServiceAccounts referenced
+	// by a pod spec which are not defined
+	CheckPodReference = "pod-reference"
+
+	// CheckScaleTarget the check ID for HorizontalPodAutoscalers whose target workload is missing
+	CheckScaleTarget = "scale-target"
+
+	// CheckDisruptionBudget the check ID for PodDisruptionBudgets whose selector does not match any pod template
+	CheckDisruptionBudget = "disruption-budget"
+)
+
+var (
+	// implicitResources the resources which exist in every namespace so can always be referenced
+	implicitResources = []string{"ServiceAccount/default", "ConfigMap/kube-root-ca.crt"}
+
+	// podSpecPaths the paths of the pod spec in each kind of workload
+	podSpecPaths = map[string][]string{
+		"Pod":                   {},
+		"Deployment":            {"spec", "template"},
+		"StatefulSet":           {"spec", "template"},
+		"DaemonSet":             {"spec", "template"},
+		"ReplicaSet":            {"spec", "template"},
+		"ReplicationController": {"spec", "template"},
+		"Job":                   {"spec", "template"},
+		"CronJob":               {"spec", "jobTemplate", "spec", "template"},
+	}
+)
+
+// Check checks that the resources refer to each other consistently. Any resource matching an entry of the allow list
+// in the form 'Kind/name' or 'Kind/*' can be referenced without being defined in the resources. Resources without a
+// namespace are treated as being in the namespace of the location if it is known or any namespace otherwise
+func Check(resources []*manifests.Manifest, co *results.ResourceLocation, allow []string) []*results.Result {
+	c := &checker{
+		resources: resources,
+		location:  co,
+		allow:     append(append([]string{}, implicitResources...), allow...),
+	}
+	for _, m := range resources {
+		switch m.Kind() {
+		case "Service":
+			c.checkService(m)
+		case "Ingress":
+			c.checkIngress(m)
+		case "HorizontalPodAutoscaler":
+			c.checkScaleTarget(m)
+		case "PodDisruptionBudget":
+			c.checkDisruptionBudget(m)
+		}
+		if podSpecPaths[m.Kind()] != nil {
+			c.checkPodReferences(m)
+		}
+	}
+	return c.results
+}
+
+// checker checks the references between a set of resources
+type checker struct {
+	resources []*manifests.Manifest
+	location  *results.ResourceLocation
+	allow     []string
+	results   []*results.Result
+}
+
+// addResult adds a result for the check of the resource which failed if there is a message
+func (c *checker) addResult(m *manifests.Manifest, checkID, message string) {
+	r := &results.Result{
+		Tool:      ToolName,
+		Location:  c.location,
+		Kind:      m.Kind(),
+		Name:      m.Name(),
+		Namespace: m.Namespace(),
+		File:      m.File,
+		CheckID:   checkID,
+		Status:    results.StatusPassed,
+	}
+	if message != "" {
+		r.Status = results.StatusFailed
+		r.Severity = results.SeverityError
+		r.Message = message
+	}
+	c.results = append(c.results, r)
+}
+
+// addResults adds a failed result for each message or a passed result if there are none
+func (c *checker) addResults(m *manifests.Manifest, checkID string, messages []string) {
+	if len(messages) == 0 {
+		c.addResult(m, checkID, "")
+		return
+	}
+	for _, message := range messages {
+		c.addResult(m, checkID, message)
+	}
+}
+
+// find returns the resource of the given kind and name in the same namespace as the referring resource
+func (c *checker) find(from *manifests.Manifest, kind, name string) *manifests.Manifest {
+	for _, m := range c.resources {
+		if m.Kind() == kind && m.Name() == name && c.sameNamespace(m, from) {
+			return m
+		}
+	}
+	return nil
+}
+
+// namespace returns the namespace of the resource or the namespace of the location if it does not have one
+func (c *checker) namespace(m *manifests.Manifest) string {
+	ns := m.Namespace()
+	if ns == "" && c.location != nil {
+		ns = c.location.Namespace
+	}
+	return ns
+}
+
+// sameNamespace returns true if the resources are installed into the same namespace. Charts often only set the
+// namespace on some templates so a resource without a namespace is in the namespace of the location. If that is not
+// known it could be installed into the namespace of any other resource
+func (c *checker) sameNamespace(m, other *manifests.Manifest) bool {
+	ns := c.namespace(m)
+	otherNS := c.namespace(other)
+	return ns == "" || otherNS == "" || ns == otherNS
+}
+
+// isAllowed returns true if the resource can be referenced without being defined
+func (c *checker) isAllowed(kind, name string) bool {
+	for _, a := range c.allow {
+		if a == kind+"/"+name || a == kind+"/*" {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPodTemplate returns true if the selector matches the pod template labels of any workload in the namespace
+func (c *checker) matchesPodTemplate(from *manifests.Manifest, selector map[string]string) bool {
+	for _, m := range c.resources {
+		if podSpecPaths[m.Kind()] == nil || !c.sameNamespace(m, from) {
+			continue
+		}
+		labels, _, _ := unstructured.NestedStringMap(m.Object.Object, append(podSpecPaths[m.Kind()], "metadata", "labels")...)
+		if matchesLabels(selector, labels) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *checker) checkService(m *manifests.Manifest) {
+	serviceType, _, _ := unstructured.NestedString(m.Object.Object, "spec", "type")
+	selector, _, _ := unstructured.NestedStringMap(m.Object.Object, "spec", "selector")
+	if serviceType == "ExternalName" || len(selector) == 0 {
+		return
+	}
+	message := ""
+	if !c.matchesPodTemplate(m, selector) {
+		message = fmt.Sprintf("the selector %s does not match the labels of any pod template", formatLabels(selector))
+	}
+	c.addResult(m, CheckServiceSelector, message)
+}
+
+func (c *checker) checkIngress(m *manifests.Manifest) {
+	var messages []string
+	checkBackend := func(backend map[string]interface{}) {
+		if backend == nil {
+			return
+		}
+		// networking.k8s.io/v1 uses service.name and service.port while the beta versions use serviceName and servicePort
+		name, _, _ := unstructured.NestedString(backend, "serviceName")
+		port, _, _ := unstructured.NestedFieldNoCopy(backend, "servicePort")
+		if name == "" {
+			name, _, _ = unstructured.NestedString(backend, "service", "name")
+			port, _, _ = unstructured.NestedFieldNoCopy(backend, "service", "port", "number")
+			if port == nil {
+				port, _, _ = unstructured.NestedFieldNoCopy(backend, "service", "port", "name")
+			}
+		}
+		if name == "" {
+			return
+		}
+		svc := c.find(m, "Service", name)
+		if svc == nil {
+			if !c.isAllowed("Service", name) {
+				messages = append(messages, fmt.Sprintf("the backend Service %s does not exist", name))
+			}
+			return
+		}
+		if port != nil && !hasServicePort(svc, port) {
+			messages = append(messages, fmt.Sprintf("the backend Service %s does not have the port %v", name, port))
+		}
+	}
+
+	for _, path := range [][]string{{"spec", "defaultBackend"}, {"spec", "backend"}} {
+		backend, _, _ := unstructured.NestedMap(m.Object.Object, path...)
+		checkBackend(backend)
+	}
+	rules, _, _ := unstructured.NestedSlice(m.Object.Object, "spec", "rules")
+	for _, rule := range rules {
+		ruleMap, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		paths, _, _ := unstructured.NestedSlice(ruleMap, "http", "paths")
+		for _, p := range paths {
+			pathMap, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			backend, _, _ := unstructured.NestedMap(pathMap, "backend")
+			checkBackend(backend)
+		}
+	}
+	c.addResults(m, CheckIngressBackend, messages)
+}
+
+func (c *checker) checkPodReferences(m *manifests.Manifest) {
+	template := m.Object.Object
+	path := podSpecPaths[m.Kind()]
+	if len(path) > 0 {
+		template, _, _ = unstructured.NestedMap(m.Object.Object, path...)
+	}
+	spec, _, _ := unstructured.NestedMap(template, "spec")
+	if spec == nil {
+		return
+	}
+
+	// the claims of a StatefulSet are created from its volume claim templates
+	claimTemplates := map[string]bool{}
+	templates, _, _ := unstructured.NestedSlice(m.Object.Object, "spec", "volumeClaimTemplates")
+	for _, t := range templates {
+		if tm, ok := t.(map[string]interface{}); ok {
+			name, _, _ := unstructured.NestedString(tm, "metadata", "name")
+			claimTemplates[name] = true
+		}
+	}
+
+	refs := map[string]bool{}
+	addRef := func(kind string, obj map[string]interface{}, fields ...string) {
+		name, _, _ := unstructured.NestedString(obj, fields...)
+		optional, _, _ := unstructured.NestedBool(obj, append(fields[:len(fields)-1:len(fields)-1], "optional")...)
+		if name == "" || optional {
+			return
+		}
+		if kind == "PersistentVolumeClaim" && claimTemplates[name] {
+			return
+		}
+		refs[kind+"/"+name] = true
+	}
+
+	serviceAccount, _, _ := unstructured.NestedString(spec, "serviceAccountName")
+	if serviceAccount == "" {
+		serviceAccount, _, _ = unstructured.NestedString(spec, "serviceAccount")
+	}
+	if serviceAccount != "" {
+		refs["ServiceAccount/"+serviceAccount] = true
+	}
+	for _, s := range nestedMaps(spec, "imagePullSecrets") {
+		addRef("Secret", s, "name")
+	}
+	for _, v := range nestedMaps(spec, "volumes") {
+		addRef("ConfigMap", v, "configMap", "name")
+		addRef("Secret", v, "secret", "secretName")
+		addRef("PersistentVolumeClaim", v, "persistentVolumeClaim", "claimName")
+		for _, source := range nestedMaps(v, "projected", "sources") {
+			addRef("ConfigMap", source, "configMap", "name")
+			addRef("Secret", source, "secret", "name")
+		}
+	}
+	for _, containers := range []string{"initContainers", "containers"} {
+		for _, container := range nestedMaps(spec, containers) {
+			for _, env := range nestedMaps(container, "env") {
+				addRef("ConfigMap", env, "valueFrom", "configMapKeyRef", "name")
+				addRef("Secret", env, "valueFrom", "secretKeyRef", "name")
+			}
+			for _, envFrom := range nestedMaps(container, "envFrom") {
+				addRef("ConfigMap", envFrom, "configMapRef", "name")
+				addRef("Secret", envFrom, "secretRef", "name")
+			}
+		}
+	}
+
+	var messages []string
+	for ref := range refs {
+		parts := strings.SplitN(ref, "/", 2)
+		kind, name := parts[0], parts[1]
+		if c.find(m, kind, name) == nil && !c.isAllowed(kind, name) {
+			messages = append(messages, fmt.Sprintf("the %s %s is not defined", kind, name))
+		}
+	}
+	sort.Strings(messages)
+	c.addResults(m, CheckPodReference, messages)
+}
+
+func (c *checker) checkScaleTarget(m *manifests.Manifest) {
+	kind, _, _ := unstructured.NestedString(m.Object.Object, "spec", "scaleTargetRef", "kind")
+	name, _, _ := unstructured.NestedString(m.Object.Object, "spec", "scaleTargetRef", "name")
+	if kind == "" || name == "" {
+		return
+	}
+	message := ""
+	if c.find(m, kind, name) == nil && !c.isAllowed(kind, name) {
+		message = fmt.Sprintf("the scale target %s %s does not exist", kind, name)
+	}
+	c.addResult(m, CheckScaleTarget, message)
+}
+
+func (c *checker) checkDisruptionBudget(m *manifests.Manifest) {
+	selector, _, _ := unstructured.NestedStringMap(m.Object.Object, "spec", "selector", "matchLabels")
+	if len(selector) == 0 {
+		return
+	}
+	message := ""
+	if !c.matchesPodTemplate(m, selector) {
+		message = fmt.Sprintf("the selector %s does not match the labels of any pod template", formatLabels(selector))
+	}
+	c.addResult(m, CheckDisruptionBudget, message)
+}
+
+// hasServicePort returns true if the Service has a port with the given number or name
+func hasServicePort(svc *manifests.Manifest, port interface{}) bool {
+	target := fmt.Sprint(port)
+	for _, p := range nestedMaps(svc.Object.Object, "spec", "ports") {
+		name, _, _ := unstructured.NestedString(p, "name")
+		number, _, _ := unstructured.NestedFieldNoCopy(p, "port")
+		if (name != "" && name == target) || fmt.Sprint(number) == target {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedMaps returns the maps in the slice at the given path
+func nestedMaps(obj map[string]interface{}, fields ...string) []map[string]interface{} {
+	values, _, _ := unstructured.NestedFieldNoCopy(obj, fields...)
+	items, ok := values.([]interface{})
+	if !ok {
+		return nil
+	}
+	var answer []map[string]interface{}
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			answer = append(answer, m)
+		}
+	}
+	return answer
+}
+
+// matchesLabels returns true if all of the selector labels are in the labels
+func matchesLabels(selector, labels map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// formatLabels formats the labels in the same way as kubectl such as 'app=myapp,tier=web'
+func formatLabels(labels map[string]string) string {
+	var parts []string
+	for k, v := range labels {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
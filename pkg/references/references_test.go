@@ -0,0 +1,127 @@
+package references_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/references"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	resources, err := manifests.LoadFile(filepath.Join("test_data", "release.yaml"))
+	require.NoError(t, err, "failed to load resources")
+
+	items := references.Check(resources, nil, []string{"Secret/registry"})
+
+	var actual []string
+	for _, r := range items {
+		actual = append(actual, r.String())
+	}
+	assert.Equal(t, []string{
+		"references failed pod-reference on Deployment web: the PersistentVolumeClaim web-data is not defined",
+		"references failed pod-reference on Deployment web: the Secret web-secret is not defined",
+		"references passed pod-reference on StatefulSet db",
+		"references passed service-selector on Service web",
+		"references failed service-selector on Service orphan: the selector app=orphan does not match the labels of any pod template",
+		"references failed ingress-backend on Ingress web: the backend Service web does not have the port grpc",
+		"references failed ingress-backend on Ingress web: the backend Service api does not exist",
+		"references passed scale-target on HorizontalPodAutoscaler web",
+		"references failed scale-target on HorizontalPodAutoscaler api: the scale target Deployment api does not exist",
+		"references failed disruption-budget on PodDisruptionBudget worker: the selector app=worker does not match the labels of any pod template",
+	}, actual, "results")
+
+	items = references.Check(resources, nil, []string{"Secret/*", "PersistentVolumeClaim/web-data", "Service/api", "Deployment/api"})
+	for _, r := range items {
+		if r.Kind == "Deployment" || r.Kind == "HorizontalPodAutoscaler" {
+			assert.False(t, r.Failed(), "should allow %s", r.String())
+		}
+	}
+}
+
+func TestCheckCases(t *testing.T) {
+	testCases := []struct {
+		name     string
+		file     string
+		location *results.ResourceLocation
+		allow    []string
+		expected []string
+	}{
+		{
+			name:     "empty namespaces are in the release namespace",
+			file:     "namespaces.yaml",
+			location: &results.ResourceLocation{Namespace: "jx"},
+			expected: []string{
+				"references failed pod-reference on Deployment jx/web: the Secret other-secret is not defined",
+				"references failed service-selector on Service other/web: the selector app=web does not match the labels of any pod template",
+			},
+		},
+		{
+			name: "empty namespaces match any namespace if the release namespace is not known",
+			file: "namespaces.yaml",
+			expected: []string{
+				"references failed pod-reference on Deployment jx/web: the Secret other-secret is not defined",
+				"references failed service-selector on Service other/web: the selector app=web does not match the labels of any pod template",
+			},
+		},
+		{
+			name:     "empty namespaces are not in other namespaces",
+			file:     "namespaces.yaml",
+			location: &results.ResourceLocation{Namespace: "other"},
+			expected: []string{
+				"references failed pod-reference on Deployment jx/web: the ConfigMap web-config is not defined",
+				"references failed pod-reference on Deployment jx/web: the Secret other-secret is not defined",
+				"references failed service-selector on Service web: the selector app=web does not match the labels of any pod template",
+				"references failed service-selector on Service other/web: the selector app=web does not match the labels of any pod template",
+			},
+		},
+		{
+			name: "ingress ports by number and name",
+			file: "ingress.yaml",
+			expected: []string{
+				"references failed ingress-backend on Ingress web: the backend Service web does not have the port 8080",
+				"references failed ingress-backend on Ingress web: the backend Service web does not have the port grpc",
+				"references failed ingress-backend on Ingress legacy: the backend Service api does not exist",
+			},
+		},
+		{
+			name: "optional references, claim templates and implicit resources",
+			file: "pods.yaml",
+			expected: []string{
+				"references failed pod-reference on StatefulSet db: the PersistentVolumeClaim logs is not defined",
+				"references failed pod-reference on CronJob backup: the ConfigMap projected-config is not defined",
+				"references failed pod-reference on CronJob backup: the ServiceAccount backup is not defined",
+			},
+		},
+		{
+			name:  "allow list",
+			file:  "pods.yaml",
+			allow: []string{"ConfigMap/*", "ServiceAccount/backup", "PersistentVolumeClaim/logs"},
+		},
+		{
+			name:  "allow list by name only",
+			file:  "pods.yaml",
+			allow: []string{"ConfigMap/other", "ServiceAccount/backup"},
+			expected: []string{
+				"references failed pod-reference on StatefulSet db: the PersistentVolumeClaim logs is not defined",
+				"references failed pod-reference on CronJob backup: the ConfigMap projected-config is not defined",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		resources, err := manifests.LoadFile(filepath.Join("test_data", tc.file))
+		require.NoError(t, err, "failed to load resources for %s", tc.name)
+
+		var actual []string
+		for _, r := range references.Check(resources, tc.location, tc.allow) {
+			if r.Failed() {
+				actual = append(actual, r.String())
+			}
+		}
+		assert.Equal(t, tc.expected, actual, "failed results for %s", tc.name)
+	}
+}
@@ -0,0 +1,56 @@
+package reports
+
+import (
+	"github.com/jenkins-x/jx-helpers/v3/pkg/yamls"
+	"github.com/pkg/errors"
+)
+
+const (
+	// IndexFileName the name of the index file in the output dir listing all of the reports
+	IndexFileName = "index.yaml"
+)
+
+// Index lists all of the reports generated in a run
+type Index struct {
+	// Reports the reports generated
+	Reports []IndexEntry `json:"reports,omitempty"`
+}
+
+// IndexEntry a report generated by a tool for a location
+type IndexEntry struct {
+	// Tool the name of the tool which generated the report or empty for a combined report of all the tools
+	Tool string `json:"tool,omitempty"`
+
+	// Description the description of the location tested
+	Description string `json:"description,omitempty"`
+
+	// Chart the chart dir if the report is for a chart
+	Chart string `json:"chart,omitempty"`
+
+	// Release the release name if the report is for a chart
+	Release string `json:"release,omitempty"`
+
+	// SourceDir the resources dir if the report is for some resources
+	SourceDir string `json:"sourceDir,omitempty"`
+
+	// Version the target kubernetes version if the tests were run against multiple versions
+	Version string `json:"kubernetesVersion,omitempty"`
+
+	// Format the format of the report
+	Format string `json:"format,omitempty"`
+
+	// File the report file relative to the output dir
+	File string `json:"file"`
+
+	// Failed if the tool failed
+	Failed bool `json:"failed,omitempty"`
+}
+
+// SaveIndex saves the index to the given file
+func SaveIndex(path string, index *Index) error {
+	err := yamls.SaveFile(index, path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save index file %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package reports
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/pkg/errors"
+)
+
+// JSONReport a report of all of the results of a run
+type JSONReport struct {
+	Summary Summary      `json:"summary"`
+	Results []JSONResult `json:"results"`
+}
+
+// Summary a summary of the number of results
+type Summary struct {
+	Total      int `json:"total"`
+	Passed     int `json:"passed"`
+	Failed     int `json:"failed"`
+	Skipped    int `json:"skipped"`
+	Waived     int `json:"waived,omitempty"`
+	Suppressed int `json:"suppressed,omitempty"`
+}
+
+// JSONResult a single result in the JSON report
+type JSONResult struct {
+	Tool        string           `json:"tool"`
+	Rule        int              `json:"rule"`
+	Description string           `json:"description,omitempty"`
+	Chart       string           `json:"chart,omitempty"`
+	Release     string           `json:"release,omitempty"`
+	SourceDir   string           `json:"sourceDir,omitempty"`
+	Version     string           `json:"kubernetesVersion,omitempty"`
+	Kind        string           `json:"kind,omitempty"`
+	Name        string           `json:"name,omitempty"`
+	Namespace   string           `json:"namespace,omitempty"`
+	File        string           `json:"file,omitempty"`
+	CheckID     string           `json:"checkId,omitempty"`
+	Status      results.Status   `json:"status"`
+	Severity    results.Severity `json:"severity,omitempty"`
+	Message     string           `json:"message,omitempty"`
+	DurationMS  int64            `json:"durationMs,omitempty"`
+}
+
+// WriteJSON writes the results as a JSON report
+func WriteJSON(w io.Writer, items []*results.Result) error {
+	report := &JSONReport{
+		Summary: Summarize(items),
+		Results: []JSONResult{},
+	}
+	for _, r := range items {
+		jr := JSONResult{
+			Tool:       r.Tool,
+			Kind:       r.Kind,
+			Name:       r.Name,
+			Namespace:  r.Namespace,
+			File:       r.File,
+			CheckID:    r.CheckID,
+			Status:     r.Status,
+			Severity:   r.Severity,
+			Message:    r.Message,
+			DurationMS: r.Duration.Milliseconds(),
+		}
+		if co := r.Location; co != nil {
+			jr.Rule = co.Rule
+			jr.Description = co.Description
+			jr.Chart = co.Chart
+			jr.Release = co.Release
+			jr.SourceDir = co.SourceDir
+			jr.Version = co.KubernetesVersion
+		}
+		report.Results = append(report.Results, jr)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(report)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode JSON report")
+	}
+	return nil
+}
+
+// Summarize returns a summary of the results
+func Summarize(items []*results.Result) Summary {
+	s := Summary{
+		Total: len(items),
+	}
+	for _, r := range items {
+		switch r.Status {
+		case results.StatusPassed:
+			s.Passed++
+		case results.StatusFailed:
+			s.Failed++
+		case results.StatusSkipped:
+			s.Skipped++
+		case results.StatusWaived:
+			s.Waived++
+		case results.StatusSuppressed:
+			s.Suppressed++
+		}
+	}
+	return s
+}
@@ -0,0 +1,156 @@
+package reports
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/pkg/errors"
+)
+
+// JUnitTestSuites the root element of a JUnit report
+type JUnitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+}
+
+// JUnitTestSuite a test suite for each chart release or resources dir
+type JUnitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []JUnitTestCase `xml:"testcase"`
+}
+
+// JUnitTestCase a test case for each check on a resource
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+	Skipped   *JUnitSkipped `xml:"skipped,omitempty"`
+}
+
+// JUnitFailure the failure of a test case
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnitSkipped marks a test case as skipped
+type JUnitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// WriteJUnit writes the results as a JUnit XML report
+func WriteJUnit(w io.Writer, items []*results.Result) error {
+	report := ToJUnit(items)
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write XML header")
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(report)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode JUnit XML")
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// ToJUnit converts the results to a JUnit report with a test suite for each location
+func ToJUnit(items []*results.Result) *JUnitTestSuites {
+	report := &JUnitTestSuites{}
+	var total time.Duration
+	locations, m := GroupByLocation(items)
+	for _, co := range locations {
+		suite := JUnitTestSuite{
+			Name: locationName(co),
+		}
+
+		// each tool runs once per location so only count its duration once
+		durations := map[string]time.Duration{}
+		for _, r := range m[co] {
+			durations[r.Tool] = r.Duration
+
+			name := r.CheckID
+			resource := r.ResourceName()
+			if resource == "" {
+				resource = r.File
+			}
+			if resource != "" {
+				name = resource + " " + name
+			}
+			tc := JUnitTestCase{
+				Name:      name,
+				ClassName: r.Tool,
+				File:      r.File,
+			}
+			switch r.Status {
+			case results.StatusFailed:
+				tc.Failure = &JUnitFailure{
+					Message: firstLine(r.Message),
+					Type:    string(r.Severity),
+					Text:    r.Message,
+				}
+				suite.Failures++
+			case results.StatusSkipped:
+				tc.Skipped = &JUnitSkipped{
+					Message: r.Message,
+				}
+				suite.Skipped++
+			case results.StatusWaived, results.StatusSuppressed:
+				tc.Skipped = &JUnitSkipped{
+					Message: string(r.Status) + ": " + r.Message,
+				}
+				suite.Skipped++
+			}
+			suite.TestCases = append(suite.TestCases, tc)
+			suite.Tests++
+		}
+
+		var d time.Duration
+		for _, v := range durations {
+			d += v
+		}
+		suite.Time = formatSeconds(d)
+		total += d
+
+		report.Suites = append(report.Suites, suite)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+	}
+	report.Time = formatSeconds(total)
+	return report
+}
+
+func locationName(co *results.ResourceLocation) string {
+	if co == nil {
+		return "unknown"
+	}
+	return co.Description
+}
+
+func firstLine(text string) string {
+	for i, c := range text {
+		if c == '\n' {
+			return text[:i]
+		}
+	}
+	return text
+}
+
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,113 @@
+package reports
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/pkg/errors"
+)
+
+const (
+	// FormatJSON the JSON report format
+	FormatJSON = "json"
+
+	// FormatJUnit the JUnit XML report format
+	FormatJUnit = "junit"
+
+	// FormatSarif the SARIF 2.1.0 report format
+	FormatSarif = "sarif"
+)
+
+// Writer writes a report of the results
+type Writer func(w io.Writer, results []*results.Result) error
+
+// Writers the report writers for each of the report formats
+var Writers = map[string]Writer{
+	FormatJSON:  WriteJSON,
+	FormatJUnit: WriteJUnit,
+	FormatSarif: WriteSarif,
+}
+
+// IsReportFormat returns true if the format is only generated from the results of all the tools
+// rather than being a format which is passed to each tool
+func IsReportFormat(format string) bool {
+	return Writers[format] != nil && format != FormatJSON
+}
+
+// Formats returns the supported report formats
+func Formats() []string {
+	var answer []string
+	for k := range Writers {
+		answer = append(answer, k)
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+// FormatForFile returns the report format for the given file name based on its extension
+func FormatForFile(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		return FormatJUnit
+	case ".sarif":
+		return FormatSarif
+	default:
+		return FormatJSON
+	}
+}
+
+// FileName returns the default file name of a report in the given format
+func FileName(format string) string {
+	switch format {
+	case FormatJSON:
+		return "report.json"
+	case FormatJUnit:
+		return "junit.xml"
+	case FormatSarif:
+		return "results.sarif"
+	default:
+		return "report." + format
+	}
+}
+
+// WriteFile writes the report in the given format to the file
+func WriteFile(path string, format string, items []*results.Result) error {
+	writer := Writers[format]
+	if writer == nil {
+		return errors.Errorf("unsupported report format %s", format)
+	}
+	dir := filepath.Dir(path)
+	err := os.MkdirAll(dir, files.DefaultDirWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create dir %s", dir)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create file %s", path)
+	}
+	defer f.Close()
+
+	err = writer(f, items)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %s report to %s", format, path)
+	}
+	return nil
+}
+
+// GroupByLocation groups the results by their location preserving the order in which the locations were tested
+func GroupByLocation(items []*results.Result) ([]*results.ResourceLocation, map[*results.ResourceLocation][]*results.Result) {
+	var locations []*results.ResourceLocation
+	m := map[*results.ResourceLocation][]*results.Result{}
+	for _, r := range items {
+		if _, ok := m[r.Location]; !ok {
+			locations = append(locations, r.Location)
+		}
+		m[r.Location] = append(m[r.Location], r)
+	}
+	return locations, m
+}
@@ -0,0 +1,145 @@
+package reports_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/reports"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// generateTestOutput enable to regenerate the expected output
+	generateTestOutput = false
+)
+
+func TestReportWriters(t *testing.T) {
+	for format, writer := range reports.Writers {
+		buf := &bytes.Buffer{}
+		err := writer(buf, createTestResults())
+		require.NoError(t, err, "failed to write %s report", format)
+
+		expectedPath := filepath.Join("test_data", "expected", reports.FileName(format))
+		if generateTestOutput {
+			err = ioutil.WriteFile(expectedPath, buf.Bytes(), 0600)
+			require.NoError(t, err, "failed to save file %s", expectedPath)
+			t.Logf("saved file %s\n", expectedPath)
+			continue
+		}
+
+		expected, err := ioutil.ReadFile(expectedPath)
+		require.NoError(t, err, "failed to load %s", expectedPath)
+		assert.Equal(t, string(expected), buf.String(), "%s report", format)
+	}
+}
+
+func TestSarifInformationURIs(t *testing.T) {
+	var items []*results.Result
+	for _, tool := range []string{"assertions", "deprecations", "duplicates", "rego", "references", "custom"} {
+		items = append(items, &results.Result{
+			Tool:     tool,
+			Kind:     "Deployment",
+			Name:     "myapp",
+			CheckID:  "check",
+			Status:   results.StatusFailed,
+			Severity: results.SeverityError,
+		})
+	}
+	log := reports.ToSarif(items)
+	require.Len(t, log.Runs, len(items), "runs")
+	for _, run := range log.Runs {
+		driver := run.Tool.Driver
+		if driver.Name == "custom" {
+			assert.Empty(t, driver.InformationURI, "informationUri of a tool without a known URI")
+			continue
+		}
+		assert.Contains(t, driver.InformationURI, "jx-kube-test#", "informationUri of %s", driver.Name)
+	}
+
+	buf := &bytes.Buffer{}
+	err := reports.WriteSarif(buf, items[len(items)-1:])
+	require.NoError(t, err, "failed to write sarif report")
+	assert.NotContains(t, buf.String(), "informationUri", "should omit the informationUri if it is not known")
+}
+
+func createTestResults() []*results.Result {
+	chart := &results.ResourceLocation{
+		Description: "chart charts/myapp release default-values",
+		Chart:       filepath.Join("charts", "myapp"),
+		Release:     "default-values",
+		OutputDir:   filepath.Join("work", "charts", "myapp", "default-values"),
+	}
+	resources := &results.ResourceLocation{
+		Description: "resources config-root",
+		Rule:        1,
+		SourceDir:   "config-root",
+		OutputDir:   "config-root",
+	}
+	return []*results.Result{
+		{
+			Tool:      results.ToolKubeval,
+			Location:  chart,
+			Kind:      "Deployment",
+			Name:      "myapp",
+			Namespace: "jx",
+			File:      filepath.Join("work", "charts", "myapp", "default-values", "myapp", "templates", "deployment.yaml"),
+			CheckID:   "schema",
+			Status:    results.StatusFailed,
+			Severity:  "error",
+			Message:   "spec.replicas: Invalid type. Expected: [integer,null], given: string",
+			Duration:  2 * time.Second,
+		},
+		{
+			Tool:      results.ToolKubeScore,
+			Location:  chart,
+			Kind:      "Deployment",
+			Name:      "myapp",
+			Namespace: "jx",
+			File:      filepath.Join("work", "charts", "myapp", "default-values", "myapp", "templates", "deployment.yaml"),
+			CheckID:   "container-resources",
+			Status:    results.StatusFailed,
+			Severity:  "critical",
+			Message:   "myapp: CPU limit is not set\nmyapp: Memory limit is not set",
+			Duration:  time.Second,
+		},
+		{
+			Tool:      results.ToolKubeScore,
+			Location:  chart,
+			Kind:      "Deployment",
+			Name:      "myapp",
+			Namespace: "jx",
+			File:      filepath.Join("work", "charts", "myapp", "default-values", "myapp", "templates", "deployment.yaml"),
+			CheckID:   "stable-version",
+			Status:    results.StatusPassed,
+			Duration:  time.Second,
+		},
+		{
+			Tool:      results.ToolKubeval,
+			Location:  resources,
+			Kind:      "Service",
+			Name:      "myapp",
+			Namespace: "jx",
+			File:      filepath.Join("config-root", "namespaces", "jx", "service.yaml"),
+			CheckID:   "schema",
+			Status:    results.StatusPassed,
+			Duration:  500 * time.Millisecond,
+		},
+		{
+			Tool:      results.ToolPolaris,
+			Location:  resources,
+			Kind:      "Service",
+			Name:      "myapp",
+			Namespace: "jx",
+			File:      filepath.Join("config-root", "namespaces", "jx", "service.yaml"),
+			CheckID:   "hostIPCSet",
+			Status:    results.StatusSkipped,
+			Message:   "ignored",
+			Duration:  time.Second,
+		},
+	}
+}
@@ -0,0 +1,235 @@
+package reports
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/assertions"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/deprecations"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/duplicates"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/policy"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/references"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SarifSchema the schema of SARIF 2.1.0 reports
+	SarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	// SarifVersion the version of SARIF reports
+	SarifVersion = "2.1.0"
+
+	// readmeURL the URL of the project whose README documents the built in tests
+	readmeURL = "https://github.com/jenkins-x-plugins/jx-kube-test"
+)
+
+// ToolInformationURIs the information URIs of each tool. The built in tests refer to their section of the README. The
+// informationUri is omitted for any other tools
+var ToolInformationURIs = map[string]string{
+	results.ToolConftest:    "https://github.com/open-policy-agent/conftest",
+	results.ToolKubeconform: "https://github.com/yannh/kubeconform",
+	results.ToolKubeScore:   "https://github.com/zegl/kube-score",
+	results.ToolKubeval:     "https://github.com/jenkins-x-plugins/kubeval",
+	results.ToolPolaris:     "https://github.com/FairwindsOps/polaris",
+	assertions.ToolName:     readmeURL + "#assertions",
+	deprecations.ToolName:   readmeURL + "#deprecated-apis",
+	duplicates.ToolName:     readmeURL + "#duplicate-resources",
+	policy.ToolName:         readmeURL + "#rego-policies",
+	references.ToolName:     readmeURL + "#reference-integrity",
+}
+
+// SarifLog the root of a SARIF report
+type SarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SarifRun `json:"runs"`
+}
+
+// SarifRun the results of a single tool
+type SarifRun struct {
+	Tool    SarifTool     `json:"tool"`
+	Results []SarifResult `json:"results"`
+}
+
+// SarifTool describes the tool
+type SarifTool struct {
+	Driver SarifDriver `json:"driver"`
+}
+
+// SarifDriver describes the tool and its rules
+type SarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SarifRule `json:"rules"`
+}
+
+// SarifRule the metadata of a check
+type SarifRule struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name,omitempty"`
+	ShortDescription     SarifMessage           `json:"shortDescription"`
+	DefaultConfiguration SarifRuleConfiguration `json:"defaultConfiguration"`
+}
+
+// SarifRuleConfiguration the default configuration of a rule
+type SarifRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+// SarifMessage a message
+type SarifMessage struct {
+	Text string `json:"text"`
+}
+
+// SarifResult a finding
+type SarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   SarifMessage    `json:"message"`
+	Locations []SarifLocation `json:"locations,omitempty"`
+}
+
+// SarifLocation the location of a finding
+type SarifLocation struct {
+	PhysicalLocation *SarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []SarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+// SarifPhysicalLocation the file of a finding
+type SarifPhysicalLocation struct {
+	ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+}
+
+// SarifArtifactLocation the URI of a file
+type SarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SarifLogicalLocation the kubernetes resource of a finding
+type SarifLogicalLocation struct {
+	Name               string `json:"name,omitempty"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+// WriteSarif writes the failed results as a SARIF report
+func WriteSarif(w io.Writer, items []*results.Result) error {
+	report := ToSarif(items)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(report)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode SARIF report")
+	}
+	return nil
+}
+
+// ToSarif converts the failed results into a SARIF report with a run for each tool
+func ToSarif(items []*results.Result) *SarifLog {
+	report := &SarifLog{
+		Schema:  SarifSchema,
+		Version: SarifVersion,
+		Runs:    []SarifRun{},
+	}
+	runs := map[string]*SarifRun{}
+	var tools []string
+	ruleIndexes := map[string]map[string]int{}
+
+	for _, r := range items {
+		if !r.Failed() {
+			continue
+		}
+		run := runs[r.Tool]
+		if run == nil {
+			run = &SarifRun{
+				Tool: SarifTool{
+					Driver: SarifDriver{
+						Name:           r.Tool,
+						InformationURI: ToolInformationURIs[r.Tool],
+						Rules:          []SarifRule{},
+					},
+				},
+				Results: []SarifResult{},
+			}
+			runs[r.Tool] = run
+			tools = append(tools, r.Tool)
+			ruleIndexes[r.Tool] = map[string]int{}
+		}
+
+		level := SarifLevel(r.Severity)
+		ruleID := r.CheckID
+		if ruleID == "" {
+			ruleID = r.Tool
+		}
+		idx, ok := ruleIndexes[r.Tool][ruleID]
+		if !ok {
+			idx = len(run.Tool.Driver.Rules)
+			ruleIndexes[r.Tool][ruleID] = idx
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, SarifRule{
+				ID:   ruleID,
+				Name: ruleID,
+				ShortDescription: SarifMessage{
+					Text: r.Tool + " " + ruleID,
+				},
+				DefaultConfiguration: SarifRuleConfiguration{
+					Level: level,
+				},
+			})
+		}
+
+		sr := SarifResult{
+			RuleID:    ruleID,
+			RuleIndex: idx,
+			Level:     level,
+			Message: SarifMessage{
+				Text: r.String(),
+			},
+		}
+		loc := SarifLocation{}
+		file := r.Location.SourceFile(r.File)
+		if file != "" {
+			loc.PhysicalLocation = &SarifPhysicalLocation{
+				ArtifactLocation: SarifArtifactLocation{
+					URI: filepath.ToSlash(file),
+				},
+			}
+		}
+		if r.Name != "" {
+			fqn := r.Kind + "/" + r.Name
+			if r.Namespace != "" {
+				fqn = r.Namespace + "/" + fqn
+			}
+			loc.LogicalLocations = []SarifLogicalLocation{
+				{
+					Name:               r.Name,
+					FullyQualifiedName: fqn,
+					Kind:               "resource",
+				},
+			}
+		}
+		if loc.PhysicalLocation != nil || len(loc.LogicalLocations) > 0 {
+			sr.Locations = append(sr.Locations, loc)
+		}
+		run.Results = append(run.Results, sr)
+	}
+
+	for _, tool := range tools {
+		report.Runs = append(report.Runs, *runs[tool])
+	}
+	return report
+}
+
+// SarifLevel converts the severity of a result into a SARIF level
+func SarifLevel(severity results.Severity) string {
+	switch severity {
+	case results.SeverityCritical, results.SeverityError:
+		return "error"
+	case results.SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
@@ -0,0 +1,72 @@
+package results
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// conftestResult the JSON output of conftest for a single file
+type conftestResult struct {
+	Filename   string            `json:"filename"`
+	Namespace  string            `json:"namespace"`
+	Successes  int               `json:"successes"`
+	Warnings   []conftestMessage `json:"warnings"`
+	Failures   []conftestMessage `json:"failures"`
+	Exceptions []conftestMessage `json:"exceptions"`
+}
+
+type conftestMessage struct {
+	Msg      string                 `json:"msg"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// ParseConftest parses the JSON output of conftest
+func ParseConftest(data []byte, co *ResourceLocation) ([]*Result, error) {
+	var items []conftestResult
+	err := json.Unmarshal(data, &items)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse conftest JSON output")
+	}
+
+	// conftest reports each resource of a file separately in order for each policy namespace
+	documents := map[string]int{}
+	var answer []*Result
+	for _, item := range items {
+		key := item.Namespace + "/" + item.Filename
+		documents[key]++
+		document := documents[key]
+		newResult := func(status Status, severity Severity, m *conftestMessage) *Result {
+			r := &Result{
+				Tool:     ToolConftest,
+				Location: co,
+				File:     item.Filename,
+				Document: document,
+				CheckID:  item.Namespace,
+				Status:   status,
+				Severity: severity,
+			}
+			if m != nil {
+				r.Message = m.Msg
+				if query, ok := m.Metadata["query"].(string); ok && query != "" {
+					r.CheckID = query
+				}
+			}
+			return r
+		}
+
+		for i := range item.Failures {
+			answer = append(answer, newResult(StatusFailed, SeverityError, &item.Failures[i]))
+		}
+		for i := range item.Warnings {
+			answer = append(answer, newResult(StatusFailed, SeverityWarning, &item.Warnings[i]))
+		}
+		for i := range item.Exceptions {
+			answer = append(answer, newResult(StatusSkipped, "", &item.Exceptions[i]))
+		}
+		if len(item.Failures) == 0 && len(item.Warnings) == 0 && item.Successes > 0 {
+			answer = append(answer, newResult(StatusPassed, "", nil))
+		}
+	}
+	return answer, nil
+}
@@ -0,0 +1,56 @@
+package results
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// kubeconformOutput the JSON output of kubeconform
+type kubeconformOutput struct {
+	Resources []kubeconformResult `json:"resources"`
+}
+
+// kubeconformResult the JSON output of kubeconform for a single resource
+type kubeconformResult struct {
+	Filename string `json:"filename"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Status   string `json:"status"`
+	Msg      string `json:"msg"`
+}
+
+// ParseKubeconform parses the JSON output of kubeconform
+func ParseKubeconform(data []byte, co *ResourceLocation) ([]*Result, error) {
+	output := kubeconformOutput{}
+	err := json.Unmarshal(data, &output)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse kubeconform JSON output")
+	}
+
+	var answer []*Result
+	for _, item := range output.Resources {
+		r := &Result{
+			Tool:     ToolKubeconform,
+			Location: co,
+			Kind:     item.Kind,
+			Name:     item.Name,
+			File:     item.Filename,
+			CheckID:  "schema",
+		}
+		switch item.Status {
+		case "statusValid":
+			r.Status = StatusPassed
+		case "statusSkipped", "statusEmpty":
+			r.Status = StatusSkipped
+			r.Message = item.Msg
+		default:
+			r.Status = StatusFailed
+			r.Severity = SeverityError
+			r.Message = item.Msg
+		}
+		answer = append(answer, r)
+	}
+	return answer, nil
+}
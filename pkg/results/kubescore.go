@@ -0,0 +1,100 @@
+package results
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	kubeScoreGradeCritical = 1
+	kubeScoreGradeWarning  = 5
+)
+
+// kubeScoreObject the JSON output of kube-score for a single resource
+type kubeScoreObject struct {
+	ObjectName string `json:"object_name"`
+	TypeMeta   struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+	} `json:"type_meta"`
+	ObjectMeta struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"object_meta"`
+	Checks   []kubeScoreCheck `json:"checks"`
+	FileName string           `json:"file_name"`
+}
+
+type kubeScoreCheck struct {
+	Check struct {
+		Name    string `json:"name"`
+		ID      string `json:"id"`
+		Comment string `json:"comment"`
+	} `json:"check"`
+	Grade    int  `json:"grade"`
+	Skipped  bool `json:"skipped"`
+	Comments []struct {
+		Path        string `json:"path"`
+		Summary     string `json:"summary"`
+		Description string `json:"description"`
+	} `json:"comments"`
+}
+
+// ParseKubeScore parses the JSON output of kube-score
+func ParseKubeScore(data []byte, co *ResourceLocation) ([]*Result, error) {
+	var items []kubeScoreObject
+	err := json.Unmarshal(data, &items)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse kube-score JSON output")
+	}
+
+	var answer []*Result
+	for i := range items {
+		item := &items[i]
+		for j := range item.Checks {
+			check := &item.Checks[j]
+			r := &Result{
+				Tool:      ToolKubeScore,
+				Location:  co,
+				Kind:      item.TypeMeta.Kind,
+				Name:      item.ObjectMeta.Name,
+				Namespace: item.ObjectMeta.Namespace,
+				File:      item.FileName,
+				CheckID:   check.Check.ID,
+			}
+			switch {
+			case check.Skipped:
+				r.Status = StatusSkipped
+			case check.Grade <= kubeScoreGradeCritical:
+				r.Status = StatusFailed
+				r.Severity = SeverityCritical
+			case check.Grade <= kubeScoreGradeWarning:
+				r.Status = StatusFailed
+				r.Severity = SeverityWarning
+			default:
+				r.Status = StatusPassed
+			}
+			if r.Failed() {
+				var messages []string
+				for _, c := range check.Comments {
+					text := c.Summary
+					if c.Path != "" {
+						text = c.Path + ": " + text
+					}
+					if c.Description != "" {
+						text += " - " + c.Description
+					}
+					messages = append(messages, text)
+				}
+				if len(messages) == 0 {
+					messages = append(messages, check.Check.Name)
+				}
+				r.Message = strings.Join(messages, "\n")
+			}
+			answer = append(answer, r)
+		}
+	}
+	return answer, nil
+}
@@ -0,0 +1,56 @@
+package results
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// kubevalResult the JSON output of kubeval for a single resource
+type kubevalResult struct {
+	Filename string   `json:"filename"`
+	Kind     string   `json:"kind"`
+	Status   string   `json:"status"`
+	Errors   []string `json:"errors"`
+}
+
+// ParseKubeval parses the JSON output of kubeval
+func ParseKubeval(data []byte, co *ResourceLocation) ([]*Result, error) {
+	var items []kubevalResult
+	err := json.Unmarshal(data, &items)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse kubeval JSON output")
+	}
+
+	// kubeval reports each resource of a file in order along with any empty documents which have no kind
+	documents := map[string]int{}
+	var answer []*Result
+	for _, item := range items {
+		document := 0
+		if item.Kind != "" {
+			documents[item.Filename]++
+			document = documents[item.Filename]
+		}
+		r := &Result{
+			Tool:     ToolKubeval,
+			Location: co,
+			Kind:     item.Kind,
+			File:     item.Filename,
+			Document: document,
+			CheckID:  "schema",
+		}
+		switch item.Status {
+		case "valid":
+			r.Status = StatusPassed
+		case "skipped":
+			r.Status = StatusSkipped
+		default:
+			r.Status = StatusFailed
+			r.Severity = SeverityError
+			r.Message = strings.Join(item.Errors, "\n")
+		}
+		answer = append(answer, r)
+	}
+	return answer, nil
+}
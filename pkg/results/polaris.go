@@ -0,0 +1,92 @@
+package results
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// polarisAudit the JSON output of a polaris audit
+type polarisAudit struct {
+	Results []polarisResult `json:"Results"`
+}
+
+type polarisResult struct {
+	Name      string                    `json:"Name"`
+	Namespace string                    `json:"Namespace"`
+	Kind      string                    `json:"Kind"`
+	Results   map[string]polarisMessage `json:"Results"`
+	PodResult *struct {
+		Results          map[string]polarisMessage `json:"Results"`
+		ContainerResults []struct {
+			Name    string                    `json:"Name"`
+			Results map[string]polarisMessage `json:"Results"`
+		} `json:"ContainerResults"`
+	} `json:"PodResult"`
+}
+
+type polarisMessage struct {
+	ID       string `json:"ID"`
+	Message  string `json:"Message"`
+	Success  bool   `json:"Success"`
+	Severity string `json:"Severity"`
+	Category string `json:"Category"`
+}
+
+// ParsePolaris parses the JSON output of a polaris audit
+func ParsePolaris(data []byte, co *ResourceLocation) ([]*Result, error) {
+	audit := &polarisAudit{}
+	err := json.Unmarshal(data, audit)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse polaris JSON output")
+	}
+
+	var answer []*Result
+	for i := range audit.Results {
+		item := &audit.Results[i]
+		addResults := func(messages map[string]polarisMessage, prefix string) {
+			keys := make([]string, 0, len(messages))
+			for k := range messages {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				m := messages[k]
+				id := m.ID
+				if id == "" {
+					id = k
+				}
+				r := &Result{
+					Tool:      ToolPolaris,
+					Location:  co,
+					Kind:      item.Kind,
+					Name:      item.Name,
+					Namespace: item.Namespace,
+					CheckID:   id,
+					Message:   prefix + m.Message,
+				}
+				switch {
+				case m.Success:
+					r.Status = StatusPassed
+				case m.Severity == "ignore":
+					r.Status = StatusSkipped
+				default:
+					r.Status = StatusFailed
+					r.Severity = ToSeverity(m.Severity)
+				}
+				answer = append(answer, r)
+			}
+		}
+
+		addResults(item.Results, "")
+		if item.PodResult != nil {
+			addResults(item.PodResult.Results, "")
+			for _, c := range item.PodResult.ContainerResults {
+				addResults(c.Results, "container "+c.Name+": ")
+			}
+		}
+	}
+	return answer, nil
+}
@@ -0,0 +1,55 @@
+package results
+
+import (
+	"path/filepath"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+)
+
+// ResolveManifests fills in any missing file or resource details of the results from the given resources which were
+// loaded from the dir. Results for a file holding several resources are resolved by their document position if known
+func ResolveManifests(results []*Result, resources []*manifests.Manifest, dir string) {
+	byFile := map[string][]*manifests.Manifest{}
+	for _, m := range resources {
+		path := filepath.Clean(m.File)
+		byFile[path] = append(byFile[path], m)
+	}
+
+	for _, r := range results {
+		if r.File != "" {
+			if r.Name != "" && r.Namespace != "" {
+				continue
+			}
+			path := filepath.Clean(r.File)
+			if !filepath.IsAbs(path) && len(byFile[path]) == 0 {
+				path = filepath.Join(dir, path)
+			}
+			candidates := byFile[path]
+			if r.Document > 0 && r.Document <= len(candidates) {
+				candidates = candidates[r.Document-1 : r.Document]
+			}
+			var matches []*manifests.Manifest
+			for _, m := range candidates {
+				if (r.Kind == "" || r.Kind == m.Kind()) && (r.Name == "" || r.Name == m.Name()) {
+					matches = append(matches, m)
+				}
+			}
+			if len(matches) == 1 {
+				m := matches[0]
+				r.Kind = m.Kind()
+				r.Name = m.Name()
+				r.Namespace = m.Namespace()
+			}
+			continue
+		}
+		if r.Name == "" {
+			continue
+		}
+		for _, m := range resources {
+			if m.Kind() == r.Kind && m.Name() == r.Name && (r.Namespace == "" || m.Namespace() == r.Namespace) {
+				r.File = m.File
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package results_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/results"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsers(t *testing.T) {
+	resourcesDir := filepath.Join("test_data", "resources")
+	deploymentFile := filepath.Join(resourcesDir, "deployment.yaml")
+
+	testCases := []struct {
+		tool     string
+		expected []results.Result
+	}{
+		{
+			tool: results.ToolKubeval,
+			expected: []results.Result{
+				{
+					Kind:      "Deployment",
+					Name:      "myapp",
+					Namespace: "jx",
+					File:      deploymentFile,
+					Document:  1,
+					CheckID:   "schema",
+					Status:    results.StatusFailed,
+					Severity:  "error",
+					Message:   "spec.replicas: Invalid type. Expected: [integer,null], given: string",
+				},
+				{
+					Kind:      "Service",
+					Name:      "myapp",
+					Namespace: "jx",
+					File:      filepath.Join(resourcesDir, "service.yaml"),
+					Document:  1,
+					CheckID:   "schema",
+					Status:    results.StatusPassed,
+				},
+			},
+		},
+		{
+			tool: results.ToolKubeconform,
+			expected: []results.Result{
+				{
+					Kind:      "Deployment",
+					Name:      "myapp",
+					Namespace: "jx",
+					File:      deploymentFile,
+					CheckID:   "schema",
+					Status:    results.StatusFailed,
+					Severity:  "error",
+					Message:   "For field spec.replicas: Invalid type. Expected: [integer,null], given: string",
+				},
+				{
+					Kind:      "Service",
+					Name:      "myapp",
+					Namespace: "jx",
+					File:      filepath.Join(resourcesDir, "service.yaml"),
+					CheckID:   "schema",
+					Status:    results.StatusPassed,
+				},
+			},
+		},
+		{
+			tool: results.ToolConftest,
+			expected: []results.Result{
+				{
+					Kind:      "Deployment",
+					Name:      "myapp",
+					Namespace: "jx",
+					File:      deploymentFile,
+					Document:  1,
+					CheckID:   "main",
+					Status:    results.StatusFailed,
+					Severity:  results.SeverityError,
+					Message:   "Containers must not run as root",
+				},
+				{
+					Kind:      "Deployment",
+					Name:      "myapp",
+					Namespace: "jx",
+					File:      deploymentFile,
+					Document:  1,
+					CheckID:   "main",
+					Status:    results.StatusFailed,
+					Severity:  results.SeverityWarning,
+					Message:   "Deployment myapp should have a team label",
+				},
+				{
+					Kind:      "Service",
+					Name:      "myapp",
+					Namespace: "jx",
+					File:      filepath.Join(resourcesDir, "service.yaml"),
+					Document:  1,
+					CheckID:   "main",
+					Status:    results.StatusPassed,
+				},
+			},
+		},
+		{
+			tool: results.ToolKubeScore,
+			expected: []results.Result{
+				{
+					Kind:      "Deployment",
+					Name:      "myapp",
+					Namespace: "jx",
+					File:      deploymentFile,
+					CheckID:   "container-resources",
+					Status:    results.StatusFailed,
+					Severity:  "critical",
+					Message:   "myapp: CPU limit is not set - Resource limits are recommended to avoid resource DDOS.",
+				},
+				{
+					Kind:      "Deployment",
+					Name:      "myapp",
+					Namespace: "jx",
+					File:      deploymentFile,
+					CheckID:   "deployment-has-poddisruptionbudget",
+					Status:    results.StatusFailed,
+					Severity:  "warning",
+					Message:   "No matching PodDisruptionBudget was found",
+				},
+				{
+					Kind:      "Deployment",
+					Name:      "myapp",
+					Namespace: "jx",
+					File:      deploymentFile,
+					CheckID:   "stable-version",
+					Status:    results.StatusPassed,
+				},
+			},
+		},
+		{
+			tool: results.ToolPolaris,
+			expected: []results.Result{
+				{
+					Kind:      "Deployment",
+					Name:      "myapp",
+					Namespace: "jx",
+					File:      deploymentFile,
+					CheckID:   "hostIPCSet",
+					Status:    results.StatusPassed,
+					Message:   "Host IPC is not configured",
+				},
+				{
+					Kind:      "Deployment",
+					Name:      "myapp",
+					Namespace: "jx",
+					File:      deploymentFile,
+					CheckID:   "runAsRootAllowed",
+					Status:    results.StatusFailed,
+					Severity:  "warning",
+					Message:   "container myapp: Should not be allowed to run as root",
+				},
+				{
+					Kind:      "Deployment",
+					Name:      "myapp",
+					Namespace: "jx",
+					File:      deploymentFile,
+					CheckID:   "tagNotSpecified",
+					Status:    results.StatusPassed,
+					Message:   "container myapp: Image tag is specified",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		path := filepath.Join("test_data", "output", tc.tool+".json")
+		data, err := ioutil.ReadFile(path)
+		require.NoError(t, err, "failed to load %s", path)
+
+		co := &results.ResourceLocation{
+			Description: "resources " + resourcesDir,
+			OutputDir:   resourcesDir,
+		}
+		parser := results.Parsers[tc.tool]
+		require.NotNil(t, parser, "no parser for %s", tc.tool)
+
+		got, err := parser(data, co)
+		require.NoError(t, err, "failed to parse %s", path)
+
+		resources, err := manifests.LoadDir(resourcesDir)
+		require.NoError(t, err, "failed to load resources from %s", resourcesDir)
+		results.ResolveManifests(got, resources, resourcesDir)
+
+		require.Len(t, got, len(tc.expected), "results for %s", tc.tool)
+		for i := range tc.expected {
+			expected := tc.expected[i]
+			expected.Tool = tc.tool
+			expected.Location = co
+			assert.Equal(t, expected, *got[i], "result %d for %s", i, tc.tool)
+		}
+	}
+}
+
+func TestSuppress(t *testing.T) {
+	dir := filepath.Join("test_data", "suppressed")
+	resources, err := manifests.LoadDir(dir)
+	require.NoError(t, err, "failed to load resources from %s", dir)
+
+	newResult := func(tool, checkID string, status results.Status) *results.Result {
+		return &results.Result{
+			Tool:      tool,
+			Kind:      "Deployment",
+			Name:      "myapp",
+			Namespace: "jx",
+			CheckID:   checkID,
+			Status:    status,
+		}
+	}
+	items := []*results.Result{
+		newResult(results.ToolKubeScore, "container-resources", results.StatusFailed),
+		newResult(results.ToolKubeScore, "deployment-has-poddisruptionbudget", results.StatusFailed),
+		newResult(results.ToolPolaris, "runAsRootAllowed", results.StatusFailed),
+		newResult(results.ToolPolaris, "hostIPCSet", results.StatusPassed),
+		newResult(results.ToolKubeval, "schema", results.StatusFailed),
+	}
+
+	count := results.Suppress(items, resources)
+	assert.Equal(t, 2, count, "suppressed count")
+
+	expected := []results.Status{
+		results.StatusSuppressed,
+		results.StatusFailed,
+		results.StatusSuppressed,
+		results.StatusPassed,
+		results.StatusFailed,
+	}
+	for i, r := range items {
+		assert.Equal(t, expected[i], r.Status, "status of %s", r.String())
+	}
+}
+
+func TestSuppressMultipleDocuments(t *testing.T) {
+	dir := filepath.Join("test_data", "multiple")
+	path := filepath.Join(dir, "output", "conftest.json")
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err, "failed to load %s", path)
+
+	resourcesDir := filepath.Join(dir, "resources")
+	co := &results.ResourceLocation{
+		Description: "resources " + resourcesDir,
+		OutputDir:   resourcesDir,
+	}
+	items, err := results.ParseConftest(data, co)
+	require.NoError(t, err, "failed to parse %s", path)
+
+	resources, err := manifests.LoadDir(resourcesDir)
+	require.NoError(t, err, "failed to load resources from %s", resourcesDir)
+	results.ResolveManifests(items, resources, resourcesDir)
+
+	count := results.Suppress(items, resources)
+	assert.Equal(t, 1, count, "suppressed count")
+
+	require.Len(t, items, 2, "results")
+	assert.Equal(t, "myapp", items[0].Name, "name of the first result")
+	assert.Equal(t, results.StatusFailed, items[0].Status, "status of %s", items[0].String())
+	assert.Equal(t, "legacy", items[1].Name, "name of the second result")
+	assert.Equal(t, results.StatusSuppressed, items[1].Status, "status of %s", items[1].String())
+}
+
+func TestIsIgnored(t *testing.T) {
+	testCases := []struct {
+		annotation string
+		tool       string
+		checkID    string
+		expected   bool
+	}{
+		{"", results.ToolKubeScore, "container-resources", false},
+		{"kube-score:container-resources", results.ToolKubeScore, "container-resources", true},
+		{"kubescore:container-resources", results.ToolKubeScore, "container-resources", true},
+		{"kube-score:container-resources", results.ToolKubeScore, "stable-version", false},
+		{"kube-score:*", results.ToolKubeScore, "stable-version", true},
+		{"polaris:runAsRootAllowed, kube-score", results.ToolKubeScore, "stable-version", true},
+		{"polaris:runAsRootAllowed", results.ToolKubeScore, "runAsRootAllowed", false},
+	}
+	for _, tc := range testCases {
+		got := results.IsIgnored(tc.annotation, tc.tool, tc.checkID)
+		assert.Equal(t, tc.expected, got, "annotation %q for %s:%s", tc.annotation, tc.tool, tc.checkID)
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	testCases := map[string]results.Severity{
+		"info":     results.SeverityInfo,
+		"Warning":  results.SeverityWarning,
+		"warn":     results.SeverityWarning,
+		"error":    results.SeverityError,
+		"deny":     results.SeverityError,
+		"CRITICAL": results.SeverityCritical,
+		"danger":   results.SeverityCritical,
+	}
+	for text, expected := range testCases {
+		got, err := results.ParseSeverity(text)
+		require.NoError(t, err, "failed to parse severity %s", text)
+		assert.Equal(t, expected, got, "severity for %s", text)
+	}
+
+	_, err := results.ParseSeverity("severe")
+	require.Error(t, err, "should fail to parse an unknown severity")
+	assert.Equal(t, results.SeverityError, results.ToSeverity("severe"), "unknown severities default to error")
+
+	assert.True(t, results.SeverityCritical.AtLeast(results.SeverityWarning), "critical is at least warning")
+	assert.True(t, results.SeverityWarning.AtLeast(results.SeverityWarning), "warning is at least warning")
+	assert.False(t, results.SeverityWarning.AtLeast(results.SeverityError), "warning is not at least error")
+
+	items := []*results.Result{
+		{Status: results.StatusFailed, Severity: results.SeverityCritical},
+		{Status: results.StatusFailed, Severity: results.SeverityWarning},
+		{Status: results.StatusPassed},
+	}
+	assert.Len(t, results.FailuresAtLeast(items, results.SeverityError), 1, "failures at least error")
+	assert.Len(t, results.FailuresAtLeast(items, results.SeverityWarning), 2, "failures at least warning")
+}
+
+func TestSourceFile(t *testing.T) {
+	workDir := filepath.Join("work", "src")
+	testCases := []struct {
+		name     string
+		location *results.ResourceLocation
+		file     string
+		expected string
+	}{
+		{
+			name: "chart",
+			location: &results.ResourceLocation{
+				Chart:     filepath.Join("charts", "myapp"),
+				OutputDir: filepath.Join(workDir, "charts", "myapp", "default-values"),
+			},
+			file:     filepath.Join(workDir, "charts", "myapp", "default-values", "myapp", "templates", "deployment.yaml"),
+			expected: filepath.Join("charts", "myapp", "templates", "deployment.yaml"),
+		},
+		{
+			name: "kustomize",
+			location: &results.ResourceLocation{
+				SourceDir: filepath.Join("overlays", "prod"),
+				OutputDir: filepath.Join(workDir, "overlays", "prod"),
+			},
+			file:     filepath.Join(workDir, "overlays", "prod", "apps_v1_deployment_myapp.yaml"),
+			expected: filepath.Join("overlays", "prod"),
+		},
+		{
+			name: "helmfile",
+			location: &results.ResourceLocation{
+				Release:   "myapp",
+				SourceDir: "helmfiles",
+				OutputDir: filepath.Join(workDir, "helmfiles", "default", "myapp"),
+			},
+			file:     filepath.Join(workDir, "helmfiles", "default", "myapp", "myapp", "templates", "deployment.yaml"),
+			expected: "helmfiles",
+		},
+		{
+			name: "resources",
+			location: &results.ResourceLocation{
+				SourceDir: "config-root",
+				OutputDir: "config-root",
+			},
+			file:     filepath.Join("config-root", "namespaces", "jx", "deployment.yaml"),
+			expected: filepath.Join("config-root", "namespaces", "jx", "deployment.yaml"),
+		},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, tc.location.SourceFile(tc.file), "source file for %s", tc.name)
+	}
+}
@@ -0,0 +1,96 @@
+package results
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Severity the normalised severity of a failed check across all of the tools
+type Severity string
+
+const (
+	// SeverityInfo an informational finding
+	SeverityInfo Severity = "info"
+
+	// SeverityWarning a finding which should be fixed but is not an error such as a kube-score or polaris warning,
+	// a conftest warn rule or a deprecated API
+	SeverityWarning Severity = "warning"
+
+	// SeverityError an error such as an invalid resource, a conftest deny rule or a removed API
+	SeverityError Severity = "error"
+
+	// SeverityCritical a critical finding such as a kube-score critical or polaris danger check
+	SeverityCritical Severity = "critical"
+)
+
+// Severities the severities in increasing order
+var Severities = []Severity{SeverityInfo, SeverityWarning, SeverityError, SeverityCritical}
+
+// severityAliases the severities reported by the tools and their normalised severity
+var severityAliases = map[string]Severity{
+	"note":   SeverityInfo,
+	"warn":   SeverityWarning,
+	"deny":   SeverityError,
+	"danger": SeverityCritical,
+}
+
+// ParseSeverity parses the given severity which can be a normalised severity or a severity reported by a tool
+// such as danger, deny or warn
+func ParseSeverity(text string) (Severity, error) {
+	name := strings.ToLower(strings.TrimSpace(text))
+	for _, s := range Severities {
+		if string(s) == name {
+			return s, nil
+		}
+	}
+	s, ok := severityAliases[name]
+	if !ok {
+		return "", errors.Errorf("unknown severity %s. Supported values: %s", text, strings.Join(SeverityNames(), ", "))
+	}
+	return s, nil
+}
+
+// ToSeverity converts the severity reported by a tool into a normalised severity defaulting to an error
+func ToSeverity(text string) Severity {
+	s, err := ParseSeverity(text)
+	if err != nil {
+		return SeverityError
+	}
+	return s
+}
+
+// SeverityNames returns the names of the severities in increasing order
+func SeverityNames() []string {
+	var answer []string
+	for _, s := range Severities {
+		answer = append(answer, string(s))
+	}
+	return answer
+}
+
+// Rank returns the rank of the severity where higher is more severe. Unknown severities are ranked as errors
+func (s Severity) Rank() int {
+	for i, v := range Severities {
+		if v == s {
+			return i
+		}
+	}
+	return SeverityError.Rank()
+}
+
+// AtLeast returns true if the severity is the same or more severe than the given threshold
+func (s Severity) AtLeast(threshold Severity) bool {
+	return s.Rank() >= threshold.Rank()
+}
+
+// FailuresAtLeast returns the failed results with a severity of at least the given threshold
+func FailuresAtLeast(results []*Result, threshold Severity) []*Result {
+	var answer []*Result
+	for _, r := range results {
+		if r.Failed() && r.Severity.AtLeast(threshold) {
+			answer = append(answer, r)
+		}
+	}
+	return answer
+}
@@ -0,0 +1,61 @@
+package results
+
+import (
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/manifests"
+)
+
+// Suppress marks any failed results as suppressed if the resource has the ignore annotation for the tool and check.
+// It returns the number of suppressed results
+func Suppress(results []*Result, resources []*manifests.Manifest) int {
+	count := 0
+	for _, r := range results {
+		if !r.Failed() || r.Name == "" {
+			continue
+		}
+		for _, m := range resources {
+			if m.Kind() != r.Kind || m.Name() != r.Name || (r.Namespace != "" && m.Namespace() != r.Namespace) {
+				continue
+			}
+			if IsIgnored(m.Object.GetAnnotations()[v1alpha1.AnnotationIgnore], r.Tool, r.CheckID) {
+				r.Status = StatusSuppressed
+				count++
+			}
+			break
+		}
+	}
+	return count
+}
+
+// IsIgnored returns true if the value of the ignore annotation suppresses the check of the tool
+func IsIgnored(annotation, tool, checkID string) bool {
+	if annotation == "" {
+		return false
+	}
+	tool = NormalizeTool(tool)
+	for _, entry := range strings.Split(annotation, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 2)
+		if NormalizeTool(parts[0]) != tool {
+			continue
+		}
+		if len(parts) == 1 {
+			return true
+		}
+		check := strings.TrimSpace(parts[1])
+		if check == "*" || check == checkID {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTool returns the tool name used in the settings file so that names such as kube-score and kubescore match
+func NormalizeTool(name string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "-", ""))
+}
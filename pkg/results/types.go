@@ -0,0 +1,185 @@
+package results
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// ToolConftest the name of the conftest tool
+	ToolConftest = "conftest"
+
+	// ToolKubeconform the name of the kubeconform tool
+	ToolKubeconform = "kubeconform"
+
+	// ToolKubeScore the name of the kube-score tool
+	ToolKubeScore = "kube-score"
+
+	// ToolKubeval the name of the kubeval tool
+	ToolKubeval = "kubeval"
+
+	// ToolPolaris the name of the polaris tool
+	ToolPolaris = "polaris"
+
+	// CheckToolError the check ID of the result added when a tool fails without reporting any failed checks
+	CheckToolError = "error"
+)
+
+// Status the status of a result
+type Status string
+
+const (
+	// StatusPassed the check passed
+	StatusPassed Status = "passed"
+
+	// StatusFailed the check failed
+	StatusFailed Status = "failed"
+
+	// StatusSkipped the check was skipped
+	StatusSkipped Status = "skipped"
+
+	// StatusWaived the check failed but the finding is waived in the baseline file
+	StatusWaived Status = "waived"
+
+	// StatusSuppressed the check failed but the resource has an annotation to ignore the check
+	StatusSuppressed Status = "suppressed"
+)
+
+// ResourceLocation the location of a set of kubernetes resources which are tested
+type ResourceLocation struct {
+	// Description a description of the location used in logging and reports
+	Description string
+
+	// Rule the index of the rule in the settings which created this location or -1 if it is not for a single rule
+	Rule int
+
+	// Chart the chart dir if the resources were generated from a chart
+	Chart string
+
+	// Release the release name used to template the chart
+	Release string
+
+	// Namespace the namespace the resources without a namespace are installed into such as the namespace of the chart
+	// release. Blank if it is not known
+	Namespace string
+
+	// SourceDir the source dir of the resources if they were not generated or the kustomize overlay or helmfile dir
+	// which generated them
+	SourceDir string
+
+	// OutputDir the directory containing the resources to test
+	OutputDir string
+
+	// Path the unique relative path of the location used to lay out the reports
+	Path string
+
+	// KubernetesVersion the target kubernetes version the resources are tested against if specified
+	KubernetesVersion string
+}
+
+// Result the result of a single check by a tool on a resource. Document is the position of the resource among the
+// resources in the file starting at 1 when the tool reports its results per resource or zero if it is not known
+type Result struct {
+	Tool      string
+	Location  *ResourceLocation
+	Kind      string
+	Name      string
+	Namespace string
+	File      string
+	Document  int
+	CheckID   string
+	Status    Status
+	Severity  Severity
+	Message   string
+	Duration  time.Duration
+}
+
+// Failed returns true if the result is a failure
+func (r *Result) Failed() bool {
+	return r.Status == StatusFailed
+}
+
+// ResourceName returns the kind and name of the resource if known
+func (r *Result) ResourceName() string {
+	name := r.Name
+	if r.Namespace != "" {
+		name = r.Namespace + "/" + name
+	}
+	if r.Kind == "" {
+		return name
+	}
+	if name == "" {
+		return r.Kind
+	}
+	return r.Kind + " " + name
+}
+
+// String returns a description of the result
+func (r *Result) String() string {
+	resource := r.ResourceName()
+	if resource == "" {
+		resource = r.File
+	}
+	text := fmt.Sprintf("%s %s", r.Tool, r.Status)
+	if r.CheckID != "" {
+		text += " " + r.CheckID
+	}
+	if resource != "" {
+		text += " on " + resource
+	}
+	if r.Message != "" {
+		text += ": " + r.Message
+	}
+	return text
+}
+
+// Parser parses the JSON output of a tool into results
+type Parser func(data []byte, co *ResourceLocation) ([]*Result, error)
+
+// Parsers the parsers for each tool
+var Parsers = map[string]Parser{
+	ToolConftest:    ParseConftest,
+	ToolKubeconform: ParseKubeconform,
+	ToolKubeScore:   ParseKubeScore,
+	ToolKubeval:     ParseKubeval,
+	ToolPolaris:     ParsePolaris,
+}
+
+// Failures returns the failed results
+func Failures(results []*Result) []*Result {
+	var answer []*Result
+	for _, r := range results {
+		if r.Failed() {
+			answer = append(answer, r)
+		}
+	}
+	return answer
+}
+
+// SourceFile returns the source file for the given file in the output dir. For charts this is the template in the
+// chart dir rather than the generated file in the work dir. The files generated from a kustomize overlay or helmfile
+// do not correspond to a single source file so the overlay or helmfile dir is returned instead
+func (co *ResourceLocation) SourceFile(file string) string {
+	if co == nil || file == "" || co.OutputDir == "" {
+		return file
+	}
+	rel, err := filepath.Rel(co.OutputDir, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return file
+	}
+	if co.Chart == "" {
+		if co.SourceDir != "" && filepath.Clean(co.SourceDir) != filepath.Clean(co.OutputDir) {
+			return co.SourceDir
+		}
+		return file
+	}
+
+	// helm template generates files in <output dir>/<chart name>/templates/...
+	parts := strings.SplitN(filepath.ToSlash(rel), "/", 2)
+	if len(parts) < 2 {
+		return file
+	}
+	return filepath.Join(co.Chart, filepath.FromSlash(parts[1]))
+}
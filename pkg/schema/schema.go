@@ -0,0 +1,210 @@
+package schema
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SchemaVersion the JSON Schema draft used by the generated schema
+	SchemaVersion = "http://json-schema.org/draft-07/schema#"
+
+	// SchemaID the ID of the generated schema
+	SchemaID = "https://jenkins-x.io/schemas/kubetest.jenkins-x.io/v1alpha1/KubeTest.json"
+)
+
+// Schema a JSON Schema
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// generator generates the schema definitions of the types using the doc comments of the source
+type generator struct {
+	docs        map[string]string
+	definitions map[string]*Schema
+}
+
+// Generate generates the JSON Schema of the KubeTest settings using the doc comments of the go source files in the
+// given v1alpha1 dir
+func Generate(sourceDir string) ([]byte, error) {
+	docs, err := loadDocs(sourceDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load the doc comments from %s", sourceDir)
+	}
+	g := &generator{
+		docs:        docs,
+		definitions: map[string]*Schema{},
+	}
+	root := g.schemaFor(reflect.TypeOf(v1alpha1.KubeTest{}), docs[v1alpha1.KindKubeTest])
+	root.Schema = SchemaVersion
+	root.ID = SchemaID
+	root.Title = v1alpha1.KindKubeTest
+	root.Definitions = g.definitions
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal the schema to JSON")
+	}
+	return append(data, '\n'), nil
+}
+
+// schemaFor returns the schema for the given type with the given description
+func (g *generator) schemaFor(t reflect.Type, description string) *Schema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schemaFor(t.Elem(), description)
+	case reflect.String:
+		return &Schema{Type: "string", Description: description}
+	case reflect.Bool:
+		return &Schema{Type: "boolean", Description: description}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Description: description}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Description: description}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Description: description, Items: g.schemaFor(t.Elem(), "")}
+	case reflect.Map:
+		return &Schema{Type: "object", Description: description, AdditionalProperties: g.schemaFor(t.Elem(), "")}
+	case reflect.Struct:
+		if t.PkgPath() != reflect.TypeOf(v1alpha1.KubeTest{}).PkgPath() {
+			// lets allow any properties in external types such as the kubernetes ObjectMeta
+			return &Schema{Type: "object", Description: description}
+		}
+		if t.Name() == v1alpha1.KindKubeTest {
+			return g.structSchema(t, description)
+		}
+		if g.definitions[t.Name()] == nil {
+			// lets register the definition before generating it in case of recursive types
+			g.definitions[t.Name()] = &Schema{}
+			*g.definitions[t.Name()] = *g.structSchema(t, g.docs[t.Name()])
+		}
+		return &Schema{Ref: "#/definitions/" + t.Name(), Description: description}
+	default:
+		return &Schema{Description: description}
+	}
+}
+
+// structSchema returns the schema of the properties of the given struct disallowing any unknown properties
+func (g *generator) structSchema(t reflect.Type, description string) *Schema {
+	answer := &Schema{
+		Type:                 "object",
+		Description:          description,
+		Properties:           map[string]*Schema{},
+		AdditionalProperties: false,
+	}
+	g.addProperties(answer, t)
+	return answer
+}
+
+// addProperties adds the properties of the fields of the given struct including any inline fields
+func (g *generator) addProperties(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || f.PkgPath != "" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		name := parts[0]
+		inline := false
+		omitEmpty := false
+		for _, p := range parts[1:] {
+			switch p {
+			case "inline":
+				inline = true
+			case "omitempty":
+				omitEmpty = true
+			}
+		}
+		if f.Anonymous && (inline || name == "") {
+			g.addProperties(s, f.Type)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = g.schemaFor(f.Type, g.docs[t.Name()+"."+f.Name])
+
+		// lets only require string values such as names and versions as structs can be empty
+		if !omitEmpty && f.Type.Kind() == reflect.String {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// loadDocs loads the doc comments of the types and fields in the go source files of the given dir keyed by
+// type name or type name and field name separated by a dot
+func loadDocs(dir string) (map[string]string, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, nil, parser.ParseComments)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse go source in dir %s", dir)
+	}
+	answer := map[string]string{}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gd, ok := decl.(*ast.GenDecl)
+				if !ok || gd.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gd.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					doc := ts.Doc
+					if doc == nil {
+						doc = gd.Doc
+					}
+					answer[ts.Name.Name] = docText(doc)
+
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+					for _, field := range st.Fields.List {
+						for _, name := range field.Names {
+							answer[ts.Name.Name+"."+name.Name] = docText(field.Doc)
+						}
+					}
+				}
+			}
+		}
+	}
+	return answer, nil
+}
+
+// docText returns the text of the doc comment without any code generation markers such as +optional
+func docText(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	var lines []string
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "+") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, " ")
+}
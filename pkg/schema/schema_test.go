@@ -0,0 +1,51 @@
+package schema_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaUpToDate(t *testing.T) {
+	data, err := schema.Generate(filepath.Join("..", "apis", "kubetest", "v1alpha1"))
+	require.NoError(t, err, "failed to generate schema")
+	assert.Equal(t, string(data), schema.JSON, "the generated schema is out of date. Please run: go run ./cmd/schema")
+
+	schemaFile := filepath.Join("..", "..", "docs", "schema", "kubetest.json")
+	docs, err := ioutil.ReadFile(schemaFile)
+	require.NoError(t, err, "failed to load %s", schemaFile)
+	assert.Equal(t, string(data), string(docs), "the schema in %s is out of date. Please run: go run ./cmd/schema", schemaFile)
+}
+
+func TestLoadValidFile(t *testing.T) {
+	path := filepath.Join("test_data", "valid.yaml")
+	settings := &v1alpha1.KubeTest{}
+	err := schema.LoadFile(path, settings)
+	require.NoError(t, err, "failed to load %s", path)
+
+	assert.Equal(t, 4, settings.Spec.Parallelism, "parallelism")
+	require.Len(t, settings.Spec.Rules, 1, "rules")
+	tests := settings.Spec.Rules[0].Tests
+	require.NotNil(t, tests.Kubeconform, "kubeconform test")
+	assert.True(t, tests.Kubeconform.Strict, "kubeconform strict")
+	assert.Equal(t, "critical", tests.Kubeconform.FailOn, "kubeconform failOn")
+	assert.NotNil(t, tests.Kubeval, "kubeval test")
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	path := filepath.Join("test_data", "invalid.yaml")
+	settings := &v1alpha1.KubeTest{}
+	err := schema.LoadFile(path, settings)
+	require.Error(t, err, "should fail to load %s", path)
+
+	message := err.Error()
+	t.Logf("got expected error: %s", message)
+	assert.Contains(t, message, path+":4: spec.parallelism: Invalid type. Expected: integer, given: string")
+	assert.Contains(t, message, path+":9: spec.rules[0].tests: Additional property kubseval is not allowed")
+	assert.Contains(t, message, path+":12: spec.rules[0].tests.polaris: Additional property argz is not allowed")
+}
@@ -0,0 +1,147 @@
+package schema
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-kube-test/pkg/apis/kubetest/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
+	yamlv3 "gopkg.in/yaml.v3"
+	"sigs.k8s.io/yaml"
+)
+
+// LoadFile loads the settings file validating it against the schema so that unknown or misspelled fields fail
+// rather than being silently ignored
+func LoadFile(path string, settings *v1alpha1.KubeTest) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read file %s", path)
+	}
+	err = Validate(path, data)
+	if err != nil {
+		return err
+	}
+	err = yaml.Unmarshal(data, settings)
+	if err != nil {
+		return errors.Wrapf(err, "failed to unmarshal YAML file %s", path)
+	}
+	return nil
+}
+
+// Validate validates the YAML of a settings file against the schema returning an error with the line number of each
+// invalid field
+func Validate(path string, data []byte) error {
+	node := &yamlv3.Node{}
+	err := yamlv3.Unmarshal(data, node)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse YAML file %s", path)
+	}
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to convert YAML file %s to JSON", path)
+	}
+	if string(jsonData) == "null" {
+		// lets treat an empty file as empty settings
+		return nil
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewStringLoader(JSON), gojsonschema.NewBytesLoader(jsonData))
+	if err != nil {
+		return errors.Wrapf(err, "failed to validate file %s against the schema", path)
+	}
+	if result.Valid() {
+		return nil
+	}
+
+	type fieldError struct {
+		line    int
+		message string
+	}
+	var fieldErrors []fieldError
+	for _, e := range result.Errors() {
+		field := e.Field()
+		if field == gojsonschema.STRING_ROOT_SCHEMA_PROPERTY {
+			field = ""
+		}
+		var fieldPath []string
+		if field != "" {
+			fieldPath = strings.Split(field, ".")
+		}
+		if e.Type() == "additional_property_not_allowed" {
+			if property, ok := e.Details()["property"].(string); ok {
+				fieldPath = append(fieldPath, property)
+			}
+		}
+		fieldErrors = append(fieldErrors, fieldError{
+			line:    findLine(node, fieldPath),
+			message: fmt.Sprintf("%s: %s", fieldName(field), e.Description()),
+		})
+	}
+	sort.SliceStable(fieldErrors, func(i, j int) bool {
+		return fieldErrors[i].line < fieldErrors[j].line
+	})
+
+	var lines []string
+	for _, fe := range fieldErrors {
+		lines = append(lines, fmt.Sprintf("%s:%d: %s", path, fe.line, fe.message))
+	}
+	return errors.Errorf("invalid settings file %s:\n%s", path, strings.Join(lines, "\n"))
+}
+
+// fieldName converts the dot separated path of a field into the form used in the documentation such as
+// spec.rules[0].tests
+func fieldName(field string) string {
+	if field == "" {
+		return "(root)"
+	}
+	var buf strings.Builder
+	for i, p := range strings.Split(field, ".") {
+		if _, err := strconv.Atoi(p); err == nil {
+			buf.WriteString("[" + p + "]")
+			continue
+		}
+		if i > 0 {
+			buf.WriteString(".")
+		}
+		buf.WriteString(p)
+	}
+	return buf.String()
+}
+
+// findLine returns the line of the node at the given path. If the path cannot be found the line of the closest parent
+// is returned
+func findLine(node *yamlv3.Node, path []string) int {
+	if node.Kind == yamlv3.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	line := node.Line
+	for _, p := range path {
+		var next *yamlv3.Node
+		switch node.Kind {
+		case yamlv3.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == p {
+					// lets use the line of the key
+					line = node.Content[i].Line
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yamlv3.SequenceNode:
+			idx, err := strconv.Atoi(p)
+			if err == nil && idx >= 0 && idx < len(node.Content) {
+				next = node.Content[idx]
+				line = next.Line
+			}
+		}
+		if next == nil {
+			return line
+		}
+		node = next
+	}
+	return line
+}
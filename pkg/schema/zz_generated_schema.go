@@ -0,0 +1,514 @@
+// Code generated by cmd/schema. DO NOT EDIT.
+
+package schema
+
+// JSON the JSON Schema of the KubeTest settings file
+const JSON = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "$id": "https://jenkins-x.io/schemas/kubetest.jenkins-x.io/v1alpha1/KubeTest.json",
+  "title": "KubeTest",
+  "description": "KubeTest represents the configuration of kube test",
+  "type": "object",
+  "properties": {
+    "apiVersion": {
+      "type": "string"
+    },
+    "kind": {
+      "type": "string"
+    },
+    "metadata": {
+      "type": "object"
+    },
+    "spec": {
+      "$ref": "#/definitions/KubeTestSpec",
+      "description": "Spec holds the desired state of the KubeTest from the client"
+    }
+  },
+  "additionalProperties": false,
+  "definitions": {
+    "Assertion": {
+      "description": "Assertion an inline check of the resources using a CEL expression",
+      "type": "object",
+      "properties": {
+        "apiVersion": {
+          "description": "APIVersion the api version of the resources to check such as apps/v1. If not specified any api version is checked",
+          "type": "string"
+        },
+        "expression": {
+          "description": "Expression the CEL expression which must be true for each resource which is available as the object variable such as 'has(object.metadata.labels.team)'",
+          "type": "string"
+        },
+        "kind": {
+          "description": "Kind the kind of the resources to check such as Deployment. If not specified any kind is checked",
+          "type": "string"
+        },
+        "message": {
+          "description": "Message the message reported if the expression is false. If not specified the expression is used",
+          "type": "string"
+        },
+        "name": {
+          "description": "Name the name of the assertion which is used as the check ID of the results",
+          "type": "string"
+        },
+        "severity": {
+          "description": "Severity the severity (info, warning, error or critical) of a failure. Defaults to error",
+          "type": "string"
+        }
+      },
+      "required": [
+        "name",
+        "expression"
+      ],
+      "additionalProperties": false
+    },
+    "ChartCase": {
+      "description": "ChartCase a named set of values and options used to template a chart as its own release",
+      "type": "object",
+      "properties": {
+        "apiVersions": {
+          "description": "APIVersions the kubernetes api versions to use for capabilities when templating the chart",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "kubeVersion": {
+          "description": "KubeVersion the kubernetes version to use when templating the chart",
+          "type": "string"
+        },
+        "name": {
+          "description": "Name the name of the test case which must be unique for the charts",
+          "type": "string"
+        },
+        "namespace": {
+          "description": "Namespace the namespace to template the chart in",
+          "type": "string"
+        },
+        "releaseName": {
+          "description": "ReleaseName the release name to template the chart with. If not specified the name of the case is used",
+          "type": "string"
+        },
+        "set": {
+          "description": "Set the values to set on the command line such as 'image.tag=1.2.3'",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "values": {
+          "description": "Values the inline values YAML to pass to helm after any values files",
+          "type": "string"
+        },
+        "valuesFiles": {
+          "description": "ValuesFiles the values files to pass to helm relative to the current directory",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        }
+      },
+      "required": [
+        "name"
+      ],
+      "additionalProperties": false
+    },
+    "Charts": {
+      "description": "Charts the charts to template and validate",
+      "type": "object",
+      "properties": {
+        "cases": {
+          "description": "Cases the test cases to template each chart with in addition to the default values and any values files found in the .jx-kube-test directory of the chart",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/ChartCase"
+          }
+        },
+        "dir": {
+          "description": "Dir the directory containing a helm chart or the source to recurse through if recursive is enabled",
+          "type": "string"
+        },
+        "recurse": {
+          "description": "Recurse if enabled recurse through the directory to find any Chart.yaml files",
+          "type": "boolean"
+        }
+      },
+      "additionalProperties": false
+    },
+    "DeprecationsTest": {
+      "description": "DeprecationsTest the configuration of the deprecated and removed kubernetes API check",
+      "type": "object",
+      "properties": {
+        "failOn": {
+          "description": "FailOn the minimum severity (warning for deprecated or error for removed APIs) which fails the test. If not specified the spec.failOn is used",
+          "type": "string"
+        },
+        "kubernetesVersion": {
+          "description": "KubernetesVersion the target kubernetes version to check against if no spec.kubernetesVersions are specified. If neither are specified any deprecated API is reported",
+          "type": "string"
+        }
+      },
+      "additionalProperties": false
+    },
+    "DuplicatesTest": {
+      "description": "DuplicatesTest the configuration of the check for duplicate and conflicting resources across the resource dirs of all of the rules",
+      "type": "object",
+      "properties": {
+        "clusterScopedKinds": {
+          "description": "ClusterScopedKinds the kinds of any additional cluster scoped resources such as custom resources whose CustomResourceDefinition is not in the resource dirs. Use 'Kind.group' such as 'ClusterIssuer.cert-manager.io' or just 'Kind' to match the kind in any API group. Custom resources whose kind is not listed here or in a CustomResourceDefinition in the resource dirs are treated as namespaced",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "failOn": {
+          "description": "FailOn the minimum severity which fails the test. If not specified the spec.failOn is used",
+          "type": "string"
+        }
+      },
+      "additionalProperties": false
+    },
+    "FailurePolicy": {
+      "description": "FailurePolicy the policy for how failing tests affect the result of the run",
+      "type": "object",
+      "properties": {
+        "advisory": {
+          "description": "Advisory the names of the tests (assertions, conftest, deprecations, duplicates, kubeconform, kubescore, kubeval, polaris, references, rego) whose failures are reported but do not fail the run",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "failFast": {
+          "description": "FailFast if enabled the run stops at the first failing test rather than running all of the remaining tests",
+          "type": "boolean"
+        }
+      },
+      "additionalProperties": false
+    },
+    "Helmfile": {
+      "description": "Helmfile the helmfile releases to template and validate",
+      "type": "object",
+      "properties": {
+        "environments": {
+          "description": "Environments the helmfile environments to template each release in. If not specified the default environment is used",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "file": {
+          "description": "File the helmfile to template. If not specified defaults to helmfile.yaml in the directory",
+          "type": "string"
+        },
+        "selectors": {
+          "description": "Selectors the optional label selectors to filter the releases to template such as 'name=myapp'",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        }
+      },
+      "additionalProperties": false
+    },
+    "KubeTestSpec": {
+      "description": "KubeTestSpec defines the configuration of kube test",
+      "type": "object",
+      "properties": {
+        "duplicates": {
+          "$ref": "#/definitions/DuplicatesTest",
+          "description": "Duplicates if specified the resources in the resource dirs of all of the rules are checked for resources which are defined more than once or with conflicting namespaces"
+        },
+        "failOn": {
+          "description": "FailOn the minimum severity (info, warning, error or critical) of a failed check which fails a test. If not specified a test fails if the tool fails. Can be overridden for each test",
+          "type": "string"
+        },
+        "failurePolicy": {
+          "$ref": "#/definitions/FailurePolicy",
+          "description": "FailurePolicy the policy for how failing tests affect the result of the run"
+        },
+        "format": {
+          "description": "Format the output format. The json, junit and sarif formats are rendered from the results of the tools. Any other format such as tap is passed to each tool which is then run twice: once for the results and once for the report",
+          "type": "string"
+        },
+        "kubernetesVersions": {
+          "description": "KubernetesVersions the target kubernetes versions to template charts and helmfile releases and validate schemas against. If specified the charts and helmfiles are templated and the tests are run once for each version",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/KubernetesVersion"
+          }
+        },
+        "outputDir": {
+          "description": "OutputDir the output directory to store the reports",
+          "type": "string"
+        },
+        "parallelism": {
+          "description": "Parallelism the maximum number of charts, values, overlays or resource dirs to template and test concurrently. Defaults to 1 which runs everything sequentially",
+          "type": "integer"
+        },
+        "rules": {
+          "description": "Rules the rules to apply",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/Rule"
+          }
+        }
+      },
+      "additionalProperties": false
+    },
+    "KubeconformTest": {
+      "description": "KubeconformTest the configuration of kubeconform tests",
+      "type": "object",
+      "properties": {
+        "args": {
+          "description": "Args optional additional comand line arguments to pass to the test",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "failOn": {
+          "description": "FailOn the minimum severity (info, warning, error or critical) of a failed check which fails the test. If not specified the spec.failOn is used",
+          "type": "string"
+        },
+        "ignoreMissingSchemas": {
+          "description": "IgnoreMissingSchemas if enabled skips resources with no schema",
+          "type": "boolean"
+        },
+        "schemaLocations": {
+          "description": "SchemaLocations additional schema locations such as for custom resource definitions. The default schema location is always used",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "skipKinds": {
+          "description": "SkipKinds the kinds of resource to skip validating such as CustomResourceDefinition",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "strict": {
+          "description": "Strict if enabled disallows additional properties not in the schema",
+          "type": "boolean"
+        },
+        "version": {
+          "description": "Version optional override of the version to use",
+          "type": "string"
+        }
+      },
+      "additionalProperties": false
+    },
+    "KubernetesVersion": {
+      "description": "KubernetesVersion a target kubernetes version to test the resources against",
+      "type": "object",
+      "properties": {
+        "apiVersions": {
+          "description": "APIVersions the additional api versions available in clusters of this version which are passed to helm template",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "version": {
+          "description": "Version the kubernetes version such as 1.21.1",
+          "type": "string"
+        }
+      },
+      "required": [
+        "version"
+      ],
+      "additionalProperties": false
+    },
+    "Kustomize": {
+      "description": "Kustomize the kustomize overlays to build and validate",
+      "type": "object",
+      "properties": {
+        "dir": {
+          "description": "Dir the directory containing the kustomization file or the overlays",
+          "type": "string"
+        },
+        "overlays": {
+          "description": "Overlays the overlay directories relative to the dir to build. If not specified the dir itself is built",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        }
+      },
+      "additionalProperties": false
+    },
+    "ReferencesTest": {
+      "description": "ReferencesTest the configuration of the check that the resources refer to each other consistently",
+      "type": "object",
+      "properties": {
+        "allow": {
+          "description": "Allow the resources which can be referenced without being defined in the resources as they are created elsewhere such as 'Secret/tls' or 'ServiceAccount/*'",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "failOn": {
+          "description": "FailOn the minimum severity which fails the test. If not specified the spec.failOn is used",
+          "type": "string"
+        }
+      },
+      "additionalProperties": false
+    },
+    "RegoTest": {
+      "description": "RegoTest the configuration of the built in evaluation of Rego policies",
+      "type": "object",
+      "properties": {
+        "allNamespaces": {
+          "description": "AllNamespaces if enabled the policies in all packages are evaluated",
+          "type": "boolean"
+        },
+        "bundles": {
+          "description": "Bundles the OPA bundle directories or tarballs containing policies and data",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "data": {
+          "description": "Data the directories or files of JSON or YAML documents available to the policies via the data keyword",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "failOn": {
+          "description": "FailOn the minimum severity (warning for warn rules or error for deny and violation rules) which fails the test. If not specified the spec.failOn is used",
+          "type": "string"
+        },
+        "namespaces": {
+          "description": "Namespaces the packages of the policies to evaluate. Defaults to main like conftest",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "policies": {
+          "description": "Policies the directories or files containing the Rego policies relative to the current directory. Defaults to the policy directory like conftest if no bundles are specified",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        }
+      },
+      "additionalProperties": false
+    },
+    "Rule": {
+      "description": "Rule the rules to apply",
+      "type": "object",
+      "properties": {
+        "charts": {
+          "$ref": "#/definitions/Charts",
+          "description": "Charts the charts to evaluate"
+        },
+        "helmfile": {
+          "$ref": "#/definitions/Helmfile",
+          "description": "Helmfile the helmfile releases to template and evaluate"
+        },
+        "kustomize": {
+          "$ref": "#/definitions/Kustomize",
+          "description": "Kustomize the kustomize overlays to build and evaluate"
+        },
+        "resources": {
+          "$ref": "#/definitions/Source",
+          "description": "Resources the kubernetes resource dir to look for resources to verify"
+        },
+        "tests": {
+          "$ref": "#/definitions/Tests",
+          "description": "Tests the tests to perform"
+        }
+      },
+      "additionalProperties": false
+    },
+    "Source": {
+      "description": "Source the location of kubernetes resources to validate",
+      "type": "object",
+      "properties": {
+        "dir": {
+          "description": "Dir the directory containing the kubernetes resources",
+          "type": "string"
+        }
+      },
+      "additionalProperties": false
+    },
+    "Test": {
+      "description": "Test a kind of test",
+      "type": "object",
+      "properties": {
+        "args": {
+          "description": "Args optional additional comand line arguments to pass to the test",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "failOn": {
+          "description": "FailOn the minimum severity (info, warning, error or critical) of a failed check which fails the test. If not specified the spec.failOn is used",
+          "type": "string"
+        },
+        "version": {
+          "description": "Version optional override of the version to use",
+          "type": "string"
+        }
+      },
+      "additionalProperties": false
+    },
+    "Tests": {
+      "description": "Tests the tests to run on the resources",
+      "type": "object",
+      "properties": {
+        "assertions": {
+          "description": "Assertions the inline CEL assertions evaluated against each resource",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/Assertion"
+          }
+        },
+        "assertionsFailOn": {
+          "description": "AssertionsFailOn the minimum severity (info, warning, error or critical) of a failed assertion which fails the test. If not specified the spec.failOn is used",
+          "type": "string"
+        },
+        "conftest": {
+          "$ref": "#/definitions/Test",
+          "description": "Conftest enables conftest tests"
+        },
+        "deprecations": {
+          "$ref": "#/definitions/DeprecationsTest",
+          "description": "Deprecations enables the built in check for deprecated and removed kubernetes APIs"
+        },
+        "kubeconform": {
+          "$ref": "#/definitions/KubeconformTest",
+          "description": "Kubeconform enables kubeconform tests"
+        },
+        "kubescore": {
+          "$ref": "#/definitions/Test",
+          "description": "Kubescore enables kube-score based tests"
+        },
+        "kubeval": {
+          "$ref": "#/definitions/Test",
+          "description": "Kubeval enables kubeval tests"
+        },
+        "polaris": {
+          "$ref": "#/definitions/Test",
+          "description": "Polaris enables polaris tests"
+        },
+        "references": {
+          "$ref": "#/definitions/ReferencesTest",
+          "description": "References enables the built in check that the resources refer to each other consistently such as Service selectors, Ingress backends and the ConfigMaps, Secrets and ServiceAccounts used by pods"
+        },
+        "rego": {
+          "$ref": "#/definitions/RegoTest",
+          "description": "Rego enables the built in evaluation of conftest compatible Rego policies without downloading conftest"
+        }
+      },
+      "additionalProperties": false
+    }
+  }
+}
+`